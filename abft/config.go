@@ -3,6 +3,8 @@ package abft
 import "github.com/Fantom-foundation/lachesis-base/utils/cachescale"
 
 type Config struct {
+	// EpochSummaries enables the cross-epoch ancestry index, which stores a summary of each sealed epoch
+	EpochSummaries bool
 }
 
 // DefaultConfig for livenet.
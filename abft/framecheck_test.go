@@ -0,0 +1,37 @@
+package abft
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/framecheck"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+)
+
+// TestFramecheck checks that frames calculated by Lachesis pass the frame precheck, including the events of cheaters
+func TestFramecheck(t *testing.T) {
+	nodes := tdag.GenNodes(7)
+	lch, _, input := FakeLachesis(nodes, nil)
+	checker := framecheck.New()
+
+	r := rand.New(rand.NewSource(0))
+	tdag.ForEachRandFork(nodes, nodes[:2], 300, 4, 10, r, tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			parents := make(dag.Events, len(e.Parents()))
+			for i, p := range e.Parents() {
+				parents[i] = input.GetEvent(p)
+			}
+			require.NoError(t, checker.Validate(e, parents), name)
+
+			input.SetEvent(e)
+			require.NoError(t, lch.Process(e))
+		},
+		Build: func(e dag.MutableEvent, name string) error {
+			e.SetEpoch(FirstEpoch)
+			return lch.Build(e)
+		},
+	})
+}
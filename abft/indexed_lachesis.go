@@ -37,6 +37,11 @@ type DagIndexer interface {
 	Reset(validators *pos.Validators, db kvdb.Store, getEvent func(hash.Event) dag.Event)
 }
 
+// DagIndexWarmer is an optional DagIndexer extension, which preloads the index caches starting from the given events
+type DagIndexWarmer interface {
+	Warmup(ids hash.Events)
+}
+
 // New creates IndexedLachesis instance.
 func NewIndexedLachesis(store *Store, input EventSource, dagIndexer DagIndexer, crit func(error), config Config) *IndexedLachesis {
 	p := &IndexedLachesis{
@@ -90,11 +95,36 @@ func (p *IndexedLachesis) Bootstrap(callback lachesis.ConsensusCallbacks) error
 				base.EpochDBLoaded(epoch)
 			}
 			p.dagIndexer.Reset(p.store.GetValidators(), p.store.epochTable.VectorIndex, p.input.GetEvent)
+			if warmer, ok := p.dagIndexer.(DagIndexWarmer); ok {
+				warmer.Warmup(p.recentRoots())
+			}
 		},
 	}
 	return p.Lachesis.BootstrapWithOrderer(callback, ordererCallbacks)
 }
 
+// recentRoots returns roots of the last decided frame and of the undecided frames, most recent first.
+// These roots are used by the election during the events reprocessing.
+func (p *IndexedLachesis) recentRoots() hash.Events {
+	var frames [][]hash.Event
+	for f := p.store.GetLastDecidedFrame(); ; f++ {
+		rr := p.store.GetFrameRoots(f)
+		if len(rr) == 0 && f > p.store.GetLastDecidedFrame() {
+			break
+		}
+		ids := make(hash.Events, len(rr))
+		for i, r := range rr {
+			ids[i] = r.ID
+		}
+		frames = append(frames, ids)
+	}
+	var roots hash.Events
+	for i := len(frames) - 1; i >= 0; i-- {
+		roots = append(roots, frames[i]...)
+	}
+	return roots
+}
+
 type uniqueID struct {
 	counter *big.Int
 }
@@ -79,10 +79,14 @@ func (p *Lachesis) applyAtropos(decidedFrame idx.Frame, atropos hash.Event) *pos
 		p.crit(err)
 	}
 
+	var sealEpoch *pos.Validators
 	if blockCallback.EndBlock != nil {
-		return blockCallback.EndBlock()
+		sealEpoch = blockCallback.EndBlock()
 	}
-	return nil
+	if sealEpoch != nil && p.config.EpochSummaries {
+		p.store.SetEpochSummary(newEpochSummary(p.store.GetEpoch(), atropos, validators, atroposVecClock, cheaters))
+	}
+	return sealEpoch
 }
 
 func (p *Lachesis) Bootstrap(callback lachesis.ConsensusCallbacks) error {
@@ -22,6 +22,7 @@ type Store struct {
 	table  struct {
 		LastDecidedState kvdb.Store `table:"c"`
 		EpochState       kvdb.Store `table:"e"`
+		EpochSummary     kvdb.Store `table:"s"`
 	}
 
 	cache struct {
@@ -0,0 +1,106 @@
+package abft
+
+import (
+	"errors"
+
+	"github.com/Fantom-foundation/lachesis-base/abft/dagidx"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/lachesis"
+)
+
+var (
+	ErrNoEpochSummary = errors.New("epoch summary is unknown")
+	ErrNotSealedEpoch = errors.New("events aren't separated by an epoch seal")
+)
+
+// EpochSummary is a lightweight summary of a sealed epoch.
+// All the events of next epochs observe the events which were observed by the last Atropos of the epoch,
+// so the summary allows to reason about causality across epochs without keeping the vector clocks.
+type EpochSummary struct {
+	Epoch   idx.Epoch
+	Atropos hash.Event
+	// Validators are the epoch validators, sorted by idx
+	Validators []idx.ValidatorID
+	// LastSeqs is the highest seq of each validator observed by the Atropos, 0 for cheaters
+	LastSeqs []idx.Event
+	Cheaters lachesis.Cheaters
+}
+
+func newEpochSummary(epoch idx.Epoch, atropos hash.Event, validators *pos.Validators, atroposVecClock dagidx.HighestBeforeSeq, cheaters lachesis.Cheaters) *EpochSummary {
+	s := &EpochSummary{
+		Epoch:      epoch,
+		Atropos:    atropos,
+		Validators: validators.SortedIDs(),
+		LastSeqs:   make([]idx.Event, validators.Len()),
+		Cheaters:   cheaters,
+	}
+	for i := range s.LastSeqs {
+		seq := atroposVecClock.Get(idx.Validator(i))
+		if !seq.IsForkDetected() {
+			s.LastSeqs[i] = seq.Seq()
+		}
+	}
+	return s
+}
+
+// LastSeq returns the highest seq of the validator which was sealed into the epoch.
+// Returns false if the validator isn't an epoch validator, or if it's a cheater.
+func (s *EpochSummary) LastSeq(validator idx.ValidatorID) (idx.Event, bool) {
+	if _, ok := s.Cheaters.Set()[validator]; ok {
+		return 0, false
+	}
+	for i, v := range s.Validators {
+		if v == validator {
+			return s.LastSeqs[i], true
+		}
+	}
+	return 0, false
+}
+
+// SetEpochSummary stores the summary of a sealed epoch.
+func (s *Store) SetEpochSummary(summary *EpochSummary) {
+	s.set(s.table.EpochSummary, summary.Epoch.Bytes(), summary)
+}
+
+// GetEpochSummary returns the summary of a sealed epoch, or nil if it's unknown.
+func (s *Store) GetEpochSummary(epoch idx.Epoch) *EpochSummary {
+	w, exists := s.get(s.table.EpochSummary, epoch.Bytes(), &EpochSummary{}).(*EpochSummary)
+	if !exists {
+		return nil
+	}
+	return w
+}
+
+// GetEpochSummaries returns summaries of sealed epochs in the [from, to] range.
+// The sequence of the epochs Atropos is a chain of custody between the epochs.
+// Stops on a first unknown summary.
+func (s *Store) GetEpochSummaries(from, to idx.Epoch) []*EpochSummary {
+	res := make([]*EpochSummary, 0, to-from+1)
+	for epoch := from; epoch <= to; epoch++ {
+		summary := s.GetEpochSummary(epoch)
+		if summary == nil {
+			break
+		}
+		res = append(res, summary)
+	}
+	return res
+}
+
+// ObservedAcrossEpochs returns true if the old event is observed by the later event from a next epoch.
+// It's true if the old event was sealed into its epoch, i.e. it was observed by the last Atropos of the epoch.
+// The result may be false positive for a fork which wasn't observed by the Atropos.
+// Use vector clocks to check causality of events from the same epoch.
+func (s *Store) ObservedAcrossEpochs(old, later dag.Event) (bool, error) {
+	if later.Epoch() <= old.Epoch() {
+		return false, ErrNotSealedEpoch
+	}
+	summary := s.GetEpochSummary(old.Epoch())
+	if summary == nil {
+		return false, ErrNoEpochSummary
+	}
+	lastSeq, ok := summary.LastSeq(old.Creator())
+	return ok && old.Seq() <= lastSeq, nil
+}
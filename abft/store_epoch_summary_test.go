@@ -0,0 +1,88 @@
+package abft
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/lachesis"
+)
+
+func TestEpochSummaries(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(5)
+	lch, store, input := FakeLachesis(nodes, nil)
+	lch.config.EpochSummaries = true
+
+	const epochs = 3
+	const maxEpochBlocks = 10
+	lch.applyBlock = func(block *lachesis.Block) *pos.Validators {
+		if lch.store.GetLastDecidedFrame()+1 == maxEpochBlocks {
+			return lch.store.GetValidators()
+		}
+		return nil
+	}
+
+	ordered := map[idx.Epoch]dag.Events{}
+	r := rand.New(rand.NewSource(0))
+	for epoch := idx.Epoch(1); epoch <= epochs; epoch++ {
+		_ = tdag.ForEachRandEvent(nodes, TestMaxEpochEvents, 3, r, tdag.ForEachEvent{
+			Process: func(e dag.Event, name string) {
+				input.SetEvent(e)
+				require.NoError(lch.Process(e))
+				ordered[epoch] = append(ordered[epoch], e)
+			},
+			Build: func(e dag.MutableEvent, name string) error {
+				if epoch != lch.store.GetEpoch() {
+					return errors.New("epoch already sealed, skip")
+				}
+				e.SetEpoch(epoch)
+				return lch.Build(e)
+			},
+		})
+		require.Equal(epoch+1, store.GetEpoch(), "epoch wasn't sealed")
+	}
+
+	require.Nil(store.GetEpochSummary(epochs + 1))
+	require.Len(store.GetEpochSummaries(1, epochs+1), epochs)
+
+	for epoch := idx.Epoch(1); epoch <= epochs; epoch++ {
+		summary := store.GetEpochSummary(epoch)
+		require.NotNil(summary)
+		require.Equal(epoch, summary.Epoch)
+		require.Equal(lch.blocks[BlockKey{epoch, maxEpochBlocks}].Atropos, summary.Atropos)
+		require.Equal(store.GetValidators().SortedIDs(), summary.Validators)
+
+		// collect the ancestors of the sealing Atropos
+		sealed := hash.EventsSet{}
+		stack := hash.Events{summary.Atropos}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if sealed.Contains(id) {
+				continue
+			}
+			sealed.Add(id)
+			stack = append(stack, input.GetEvent(id).Parents()...)
+		}
+
+		later := &tdag.TestEvent{}
+		later.SetEpoch(epoch + 1)
+		for _, e := range ordered[epoch] {
+			observed, err := store.ObservedAcrossEpochs(e, later)
+			require.NoError(err)
+			require.Equal(sealed.Contains(e.ID()), observed, e.String())
+
+			_, err = store.ObservedAcrossEpochs(e, ordered[epoch][0])
+			require.Equal(ErrNotSealedEpoch, err)
+		}
+	}
+}
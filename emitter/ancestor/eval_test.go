@@ -0,0 +1,260 @@
+package ancestor
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+// evalDAG is a recorded DAG, which defines the order of emission and the knowledge of validators
+type evalDAG struct {
+	name       string
+	validators *pos.Validators
+	events     dag.Events
+}
+
+// evalStrategies is a named combination of parents search strategies
+type evalStrategies struct {
+	name       string
+	strategies simStrategiesFn
+}
+
+// evalRow is a row of the evaluation table
+type evalRow struct {
+	dag        string
+	strategies string
+	res        simResult
+}
+
+func evalStrategiesSet() []evalStrategies {
+	return []evalStrategies{
+		{"QuorumIndexer", quorumIndexerStrategies},
+		{"FrameProgress", frameProgressStrategies},
+		{"Random", randomStrategies},
+		{"QuorumIndexer+Random", func(sim *simulation, v *simValidator, selfParent dag.Event) []SearchStrategy {
+			strategies := quorumIndexerStrategies(sim, v, selfParent)
+			for i := len(strategies) / 2; i < len(strategies); i++ {
+				strategies[i] = NewRandomStrategy(sim.r)
+			}
+			return strategies
+		}},
+	}
+}
+
+func randomStrategies(sim *simulation, _ *simValidator, _ dag.Event) []SearchStrategy {
+	strategies := make([]SearchStrategy, sim.maxParents-1)
+	for i := range strategies {
+		strategies[i] = NewRandomStrategy(sim.r)
+	}
+	return strategies
+}
+
+// asciiEvalDAG records a DAG from the ASCII scheme, with equal weights
+func asciiEvalDAG(name, scheme string) evalDAG {
+	var ordered dag.Events
+	nodes, _, _ := tdag.ASCIIschemeForEach(scheme, tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+		},
+	})
+	return evalDAG{
+		name:       name,
+		validators: pos.EqualWeightValidators(nodes, 1),
+		events:     ordered,
+	}
+}
+
+// randEvalDAG records a DAG generated by tdag.ForEachRandEvent
+func randEvalDAG(validatorsNum, eventsNum, parentsNum int, seed int64) evalDAG {
+	nodes := tdag.GenNodes(validatorsNum)
+	var ordered dag.Events
+	_ = tdag.ForEachRandEvent(nodes, eventsNum, parentsNum, rand.New(rand.NewSource(seed)), tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+		},
+	})
+	return evalDAG{
+		name:       fmt.Sprintf("rand/%dx%d/%d", validatorsNum, eventsNum, parentsNum),
+		validators: pos.EqualWeightValidators(nodes, 1),
+		events:     ordered,
+	}
+}
+
+// replay re-emits the recorded DAG with the strategies.
+// Each recorded event is replaced by an event of the same creator, which is emitted at the same position.
+// Its creator knows the replacements of the recorded event's ancestors, and chooses the parents among
+// the replacements of the recorded parents. So all the strategies are compared under the same schedule
+// and the same network delays, and only the choice of parents differs.
+func replay(recorded evalDAG, maxParents int, seed int64, strategiesFn simStrategiesFn) simResult {
+	s := newSimulation(recorded.validators, maxParents, 0, rand.New(rand.NewSource(seed)))
+	nodes := make(map[idx.ValidatorID]*simValidator, len(s.nodes))
+	delivered := make(map[idx.ValidatorID]hash.EventsSet, len(s.nodes))
+	for _, n := range s.nodes {
+		nodes[n.id] = n
+		delivered[n.id] = hash.EventsSet{}
+	}
+
+	ancestors := make(map[hash.Event]hash.EventsSet, len(recorded.events))
+	replaced := make(map[hash.Event]dag.Event, len(recorded.events))
+	for i, e := range recorded.events {
+		s.step = i
+		known := hash.EventsSet{}
+		for _, p := range e.Parents() {
+			known.Add(p)
+			for a := range ancestors[p] {
+				known.Add(a)
+			}
+		}
+		ancestors[e.ID()] = known
+
+		// deliver the replacements of the known events, in the emission order
+		v := nodes[e.Creator()]
+		for _, prev := range recorded.events[:i] {
+			if known.Contains(prev.ID()) && !delivered[v.id].Contains(prev.ID()) {
+				delivered[v.id].Add(prev.ID())
+				s.connect(v, replaced[prev.ID()])
+			}
+		}
+		var options hash.Events
+		for _, p := range e.Parents() {
+			options = append(options, replaced[p].ID())
+		}
+		replaced[e.ID()] = s.build(v, options, strategiesFn)
+		delivered[v.id].Add(e.ID())
+		s.connect(v, replaced[e.ID()])
+	}
+	return s.res
+}
+
+func writeEvalTable(w io.Writer, rows []evalRow) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "DAG\tStrategies\tEvents\tFrames/event\tElection rounds\tAtropos latency\tParents/event\t")
+	for _, row := range rows {
+		rounds, latency := "-", "-"
+		if row.res.Blocks != 0 {
+			rounds = fmt.Sprintf("%.2f", row.res.ElectionRounds())
+			latency = fmt.Sprintf("%.1f", row.res.AtroposLatency())
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.3f\t%s\t%s\t%.2f\t\n",
+			row.dag, row.strategies, row.res.Events, row.res.FramesPerEvent(), rounds, latency, row.res.ParentsPerEvent())
+	}
+	_ = tw.Flush()
+}
+
+func evaluate(dags []evalDAG, strategies []evalStrategies, maxParents int) []evalRow {
+	var rows []evalRow
+	for _, d := range dags {
+		for _, st := range strategies {
+			rows = append(rows, evalRow{
+				dag:        d.name,
+				strategies: st.name,
+				res:        replay(d, maxParents, 0, st.strategies),
+			})
+		}
+	}
+	return rows
+}
+
+func TestParentsSelectionEvaluation(t *testing.T) {
+	require := require.New(t)
+
+	dags := []evalDAG{
+		asciiEvalDAG("ascii", `
+a1.1   b1.2   c1.2   d1.2   e1.2
+║      ║      ║      ║      ║
+║      ╠──────╫───── d2.2   ║
+║      ║      ║      ║      ║
+║      b2.3 ──╫──────╣      e2.3
+║      ║      ║      ║      ║
+║      ╠──────╫───── d3.3   ║
+a2.3 ──╣      ║      ║      ║
+║      ║      ║      ║      ║
+║      b3.4 ──╣      ║      ║
+║      ║      ║      ║      ║
+║      ╠──────╫───── d4.4   ║
+║      ║      ║      ║      ║
+║      ╠───── c2.4   ║      e3.4
+║      ║      ║      ║      ║
+`),
+		randEvalDAG(5, 30, 5, 0),
+		randEvalDAG(10, 30, 10, 1),
+	}
+	rows := evaluate(dags, evalStrategiesSet(), 3)
+	require.Len(rows, len(dags)*len(evalStrategiesSet()))
+	for _, row := range rows {
+		recorded := len(dags[0].events)
+		for _, d := range dags {
+			if d.name == row.dag {
+				recorded = len(d.events)
+			}
+		}
+		require.Equal(recorded, row.res.Events, row.dag)
+		require.LessOrEqual(row.res.Parents, 3*row.res.Events)
+		require.NotZero(row.res.Frames)
+		if row.res.Blocks != 0 {
+			require.GreaterOrEqual(row.res.ElectionRounds(), 1.0)
+		}
+	}
+
+	// vector clock based strategies must be better than random choice
+	last := rows[len(rows)-len(evalStrategiesSet()):]
+	require.Equal("QuorumIndexer", last[0].strategies)
+	require.Equal("Random", last[2].strategies)
+	require.Greater(last[0].res.FramesPerEvent(), last[2].res.FramesPerEvent())
+	require.Less(last[0].res.AtroposLatency(), last[2].res.AtroposLatency())
+
+	out := &strings.Builder{}
+	writeEvalTable(out, rows)
+	t.Log("\n" + out.String())
+}
+
+// BenchmarkParentsSelectionEvaluation prints the evaluation table of the strategies on generated DAGs
+func BenchmarkParentsSelectionEvaluation(b *testing.B) {
+	dags := []evalDAG{
+		randEvalDAG(10, 50, 5, 0),
+		randEvalDAG(20, 50, 10, 0),
+		randEvalDAG(30, 30, 10, 0),
+	}
+	for _, maxParents := range []int{3, 6} {
+		var rows []evalRow
+		for _, d := range dags {
+			for _, st := range evalStrategiesSet() {
+				var total simResult
+				b.Run(fmt.Sprintf("%s/%s/%d", d.name, st.name, maxParents), func(b *testing.B) {
+					total = simResult{}
+					for i := 0; i < b.N; i++ {
+						res := replay(d, maxParents, int64(i), st.strategies)
+						total.Events += res.Events
+						total.Frames += res.Frames
+						total.Blocks += res.Blocks
+						total.Parents += res.Parents
+						total.Rounds += res.Rounds
+						total.Latency += res.Latency
+					}
+					b.ReportMetric(total.FramesPerEvent(), "frames/event")
+					b.ReportMetric(total.ElectionRounds(), "rounds")
+					b.ReportMetric(total.AtroposLatency(), "latency")
+					b.ReportMetric(total.ParentsPerEvent(), "parents/event")
+				})
+				if total.Events != 0 {
+					rows = append(rows, evalRow{d.name, fmt.Sprintf("%s/%d", st.name, maxParents), total})
+				}
+			}
+		}
+		// the table is printed with -v only, so it doesn't break the benchmark output format
+		table := &strings.Builder{}
+		writeEvalTable(table, rows)
+		b.Log("\n" + table.String())
+	}
+}
@@ -0,0 +1,140 @@
+package ancestor
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+// FairnessConfig is the coefficients of FairnessIndexer metric
+type FairnessConfig struct {
+	// PayloadCoef is the multiplier of the payload metric of a head, see PayloadIndexer
+	PayloadCoef Metric
+	// AgeCoef is the multiplier of the stake-weighted age of the payload events, which are included by a head
+	AgeCoef Metric
+	// MaxAge caps the age (in the number of processed events), so that the metric cannot overflow
+	MaxAge uint64
+	// MaxPending caps the number of tracked payload events per validator,
+	// so that the memory doesn't grow if the node doesn't emit self-events
+	MaxPending int
+}
+
+// DefaultFairnessConfig returns default coefficients.
+// A pending payload event of a validator with 1% of stake gains as much metric per processed event,
+// as 100 units of payload metric.
+func DefaultFairnessConfig() FairnessConfig {
+	return FairnessConfig{
+		PayloadCoef: 1,
+		AgeCoef:     100,
+		MaxAge:      1 << 20,
+		MaxPending:  100,
+	}
+}
+
+// pendingPayload is a payload event which isn't observed by the self-parent yet
+type pendingPayload struct {
+	seq    idx.Event
+	seenAt uint64
+}
+
+// FairnessIndexer balances the payload metric of heads with the age of the payload events which aren't yet
+// included into the self-events, so that payload of validators with a small stake or a small payload doesn't starve.
+// The age of a payload event is weighted by the stake of its creator, relative to the total stake.
+type FairnessIndexer struct {
+	cfg        FairnessConfig
+	dagi       DagIndex
+	validators *pos.Validators
+	payload    *PayloadIndexer
+
+	// processed is the number of processed events, it's used as a clock for the age
+	processed uint64
+	// pending are the payload events of each validator, which aren't observed by the self-parent
+	pending [][]pendingPayload
+}
+
+// NewFairnessIndexer creates FairnessIndexer instance.
+func NewFairnessIndexer(cfg FairnessConfig, validators *pos.Validators, dagi DagIndex, cacheSize int) *FairnessIndexer {
+	return &FairnessIndexer{
+		cfg:        cfg,
+		dagi:       dagi,
+		validators: validators,
+		payload:    NewPayloadIndexer(cacheSize),
+		pending:    make([][]pendingPayload, validators.Len()),
+	}
+}
+
+// ProcessEvent should be called for each connected event, including self-events
+func (h *FairnessIndexer) ProcessEvent(event dag.Event, payloadMetric Metric, selfEvent bool) {
+	h.payload.ProcessEvent(event, payloadMetric)
+	h.processed++
+
+	creatorIdx := h.validators.GetIdx(event.Creator())
+	if payloadMetric != 0 && !selfEvent {
+		h.addPending(creatorIdx, pendingPayload{event.Seq(), h.processed})
+	}
+	if !selfEvent {
+		return
+	}
+	// drop the payload events observed by the self-event
+	vecClock := h.dagi.GetMergedHighestBefore(event.ID())
+	for validatorIdx := range h.pending {
+		seq := vecClock.Get(idx.Validator(validatorIdx))
+		pending := h.pending[validatorIdx]
+		for len(pending) > 0 && (seq.IsForkDetected() || pending[0].seq <= seq.Seq()) {
+			pending = pending[1:]
+		}
+		h.pending[validatorIdx] = pending
+	}
+}
+
+// addPending tracks the payload event.
+// If the limit is reached, the newest tracked event is replaced, so the age of the oldest events is kept.
+// The replaced event inherits an older age, so its payload isn't delayed by the limit.
+func (h *FairnessIndexer) addPending(validatorIdx idx.Validator, p pendingPayload) {
+	pending := h.pending[validatorIdx]
+	if h.cfg.MaxPending > 0 && len(pending) >= h.cfg.MaxPending {
+		pending[len(pending)-1].seq = p.seq
+		return
+	}
+	h.pending[validatorIdx] = append(pending, p)
+}
+
+// ageOf returns the age of a pending payload event, weighted by the stake of its creator
+func (h *FairnessIndexer) ageOf(validatorIdx idx.Validator, p pendingPayload) Metric {
+	age := h.processed - p.seenAt + 1
+	if age > h.cfg.MaxAge {
+		age = h.cfg.MaxAge
+	}
+	// use the stake share in percents, so that the metric doesn't depend on the stake units
+	share := Metric(h.validators.GetWeightByIdx(validatorIdx)) * 100 / Metric(h.validators.TotalWeight())
+	if share == 0 {
+		share = 1
+	}
+	return Metric(age) * share
+}
+
+// GetMetricOf returns the payload metric of the event, plus the total stake-weighted age of the oldest
+// pending payload events of validators, which are observed by the event
+func (h *FairnessIndexer) GetMetricOf(id hash.Event) Metric {
+	metric := h.cfg.PayloadCoef * h.payload.GetMetricOf(id)
+	vecClock := h.dagi.GetMergedHighestBefore(id)
+	for validatorIdx, pending := range h.pending {
+		if len(pending) == 0 {
+			continue
+		}
+		seq := vecClock.Get(idx.Validator(validatorIdx))
+		if seq.IsForkDetected() || seq.Seq() < pending[0].seq {
+			continue
+		}
+		metric += h.cfg.AgeCoef * h.ageOf(idx.Validator(validatorIdx), pending[0])
+	}
+	return metric
+}
+
+// SearchStrategy returns a strategy which chooses heads by the fairness metric.
+// The strategy should be re-created after new events are processed.
+func (h *FairnessIndexer) SearchStrategy() SearchStrategy {
+	cache := NewMetricFnCache(h.GetMetricOf, 128)
+	return NewMetricStrategy(cache.GetMetricOf)
+}
@@ -0,0 +1,197 @@
+package ancestor
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/Fantom-foundation/lachesis-base/utils/adapters"
+	"github.com/Fantom-foundation/lachesis-base/vecfc"
+)
+
+// payloadIndexer is a common interface of PayloadIndexer and FairnessIndexer for the test
+type payloadIndexer interface {
+	ProcessEvent(event dag.Event, payloadMetric Metric, selfEvent bool)
+	SearchStrategy() SearchStrategy
+}
+
+type payloadOnlyIndexer struct {
+	*PayloadIndexer
+}
+
+func (h payloadOnlyIndexer) ProcessEvent(event dag.Event, payloadMetric Metric, _ bool) {
+	h.PayloadIndexer.ProcessEvent(event, payloadMetric)
+}
+
+// inclusionLatencies simulates a network, where every event carries a payload, and returns the maximum number
+// of emitted events until a payload event of each validator is observed by a quorum
+func inclusionLatencies(t *testing.T, validators *pos.Validators, payloads []Metric, newIndexer func(dagi DagIndex) payloadIndexer) []int {
+	const (
+		eventsNum  = 2000
+		maxParents = 2
+	)
+	r := rand.New(rand.NewSource(0))
+	sim := newSimulation(validators, maxParents, int(validators.Len()), r)
+	indexers := make(map[idx.ValidatorID]payloadIndexer, validators.Len())
+	for _, v := range sim.nodes {
+		indexers[v.id] = newIndexer(sim.dagIndex)
+	}
+	sim.onConnect = func(v *simValidator, e dag.Event) {
+		indexers[v.id].ProcessEvent(e, payloads[validators.GetIdx(e.Creator())], e.Creator() == v.id)
+	}
+	strategiesFn := func(sim *simulation, v *simValidator, _ dag.Event) []SearchStrategy {
+		strategies := make([]SearchStrategy, maxParents-1)
+		for i := range strategies {
+			strategies[i] = indexers[v.id].SearchStrategy()
+		}
+		return strategies
+	}
+
+	type pending struct {
+		e        dag.Event
+		step     int
+		observed map[idx.ValidatorID]bool
+		weight   pos.Weight
+	}
+	var pendings []*pending
+	latencies := make([]int, validators.Len())
+	for sim.step = 0; sim.step < eventsNum; sim.step++ {
+		e := sim.emit(sim.nodes[r.Intn(len(sim.nodes))], strategiesFn)
+
+		// update the latencies of the payload events, which are observed by the quorum
+		pendings = append(pendings, &pending{e, sim.step, map[idx.ValidatorID]bool{}, 0})
+		vecClock := sim.dagIndex.GetMergedHighestBefore(e.ID())
+		left := pendings[:0]
+		for _, p := range pendings {
+			creatorIdx := validators.GetIdx(p.e.Creator())
+			if !p.observed[e.Creator()] && vecClock.Get(creatorIdx).Seq() >= p.e.Seq() {
+				p.observed[e.Creator()] = true
+				p.weight += validators.Get(e.Creator())
+			}
+			if p.weight >= validators.Quorum() {
+				if latencies[creatorIdx] < sim.step-p.step {
+					latencies[creatorIdx] = sim.step - p.step
+				}
+				continue
+			}
+			left = append(left, p)
+		}
+		pendings = left
+	}
+	// events which aren't included until the end
+	for _, p := range pendings {
+		creatorIdx := validators.GetIdx(p.e.Creator())
+		if latencies[creatorIdx] < eventsNum-p.step {
+			latencies[creatorIdx] = eventsNum - p.step
+		}
+	}
+	return latencies
+}
+
+func TestFairnessIndexer_InclusionLatency(t *testing.T) {
+	// 3 validators with a big stake and a big payload, and 7 validators with a small stake and a small payload
+	weights := []pos.Weight{30, 30, 30, 1, 1, 1, 1, 1, 1, 1}
+	nodes := simValidators(len(weights)).SortedIDs()
+	validators := pos.ArrayToValidators(nodes, weights)
+	payloads := make([]Metric, validators.Len())
+	for i := range payloads {
+		payloads[i] = Metric(validators.GetWeightByIdx(idx.Validator(i))) * 100
+	}
+
+	payloadLatencies := inclusionLatencies(t, validators, payloads, func(dagi DagIndex) payloadIndexer {
+		return payloadOnlyIndexer{NewPayloadIndexer(1000)}
+	})
+	t.Logf("max inclusion latency, payload: %v", payloadLatencies)
+	fairLatencies := inclusionLatencies(t, validators, payloads, func(dagi DagIndex) payloadIndexer {
+		return NewFairnessIndexer(DefaultFairnessConfig(), validators, dagi, 1000)
+	})
+	t.Logf("max inclusion latency, fairness: %v", fairLatencies)
+
+	// every validator is included within a bounded number of events
+	for i, latency := range fairLatencies {
+		require.Less(t, latency, 15*len(nodes), nodes[i])
+	}
+	// the worst latency is better than with the payload metric only
+	require.Less(t, maxLatency(fairLatencies), maxLatency(payloadLatencies))
+}
+
+func TestFairnessIndexer_StakeWeightedAge(t *testing.T) {
+	require := require.New(t)
+
+	// c1 is older than b1, but the creator of b1 has a much bigger stake
+	_, _, named := tdag.ASCIIschemeToDAG(`
+a1   c1   b1
+`)
+	a1, b1, c1 := named["a1"], named["b1"], named["c1"]
+	builder := pos.NewBuilder()
+	builder.Set(a1.Creator(), 1)
+	builder.Set(b1.Creator(), 10)
+	builder.Set(c1.Creator(), 1)
+	validators := builder.Build()
+
+	vecClock := vecfc.NewIndex(func(err error) { panic(err) }, vecfc.LiteConfig())
+	vecClock.Reset(validators, memorydb.New(), func(id hash.Event) dag.Event {
+		for _, e := range named {
+			if e.ID() == id {
+				return e
+			}
+		}
+		return nil
+	})
+	h := NewFairnessIndexer(DefaultFairnessConfig(), validators, &adapters.VectorToDagIndexer{Index: vecClock}, 100)
+	for _, e := range []dag.Event{a1, c1, b1} {
+		require.NoError(vecClock.Add(e))
+		vecClock.Flush()
+		h.ProcessEvent(e, 1, e == a1)
+	}
+
+	// the heads have the same payload, and the old head of the high-stake creator is prioritised
+	require.Greater(h.GetMetricOf(b1.ID()), h.GetMetricOf(c1.ID()))
+	require.Equal(1, h.SearchStrategy().Choose(hash.Events{a1.ID()}, hash.Events{c1.ID(), b1.ID()}))
+}
+
+func maxLatency(latencies []int) int {
+	res := 0
+	for _, latency := range latencies {
+		if res < latency {
+			res = latency
+		}
+	}
+	return res
+}
+
+func TestFairnessIndexer_MaxPending(t *testing.T) {
+	require := require.New(t)
+
+	validators := simValidators(5)
+	cfg := DefaultFairnessConfig()
+	cfg.MaxPending = 10
+	r := rand.New(rand.NewSource(0))
+	sim := newSimulation(validators, 3, 0, r)
+	// the indexer of a node which doesn't emit self-events
+	h := NewFairnessIndexer(cfg, validators, sim.dagIndex, 100)
+	lastSeqs := make([]idx.Event, validators.Len())
+	sim.onConnect = func(v *simValidator, e dag.Event) {
+		if v == sim.nodes[0] {
+			h.ProcessEvent(e, 1, false)
+			lastSeqs[validators.GetIdx(e.Creator())] = e.Seq()
+		}
+	}
+	for sim.step = 0; sim.step < 500; sim.step++ {
+		sim.emit(sim.nodes[r.Intn(len(sim.nodes))], quorumIndexerStrategies)
+	}
+
+	for validatorIdx, pending := range h.pending {
+		require.Len(pending, cfg.MaxPending)
+		// the oldest event is kept, and the newest event is tracked
+		require.Equal(idx.Event(1), pending[0].seq)
+		require.Equal(lastSeqs[validatorIdx], pending[len(pending)-1].seq)
+	}
+}
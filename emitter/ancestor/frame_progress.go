@@ -0,0 +1,98 @@
+package ancestor
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/abft/dagidx"
+	"github.com/Fantom-foundation/lachesis-base/abft/election"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+// FrameProgressStrategy chooses parents, which make the new event a root of the highest possible frame.
+// Starting from the self-parent frame, it prefers an option which increases the frame of the new event,
+// or otherwise increases the stake of the frame roots which are forkless-caused by the new event.
+// The new event is assumed to forkless-cause a root if at least one of its parents does.
+// If no option makes a progress, the fallback strategy is used.
+type FrameProgressStrategy struct {
+	dagi          dagidx.ForklessCause
+	validators    *pos.Validators
+	getFrameRoots election.GetFrameRootsFn
+	startFrame    idx.Frame
+	fallback      SearchStrategy
+}
+
+// frameProgress is the highest frame, which roots are forkless-caused by the quorum, and the stake of
+// forkless-caused roots of the next frame
+type frameProgress struct {
+	frame  idx.Frame
+	weight pos.Weight
+}
+
+func (p frameProgress) less(b frameProgress) bool {
+	if p.frame != b.frame {
+		return p.frame < b.frame
+	}
+	return p.weight < b.weight
+}
+
+// NewFrameProgressStrategy creates FrameProgressStrategy instance.
+// selfParentFrame is the frame of the self-parent of the new event, or 0 if there's no self-parent.
+// fallback may be nil, then the first option is chosen if no option makes a progress.
+func NewFrameProgressStrategy(validators *pos.Validators, dagi dagidx.ForklessCause, getFrameRoots election.GetFrameRootsFn, selfParentFrame idx.Frame, fallback SearchStrategy) *FrameProgressStrategy {
+	if selfParentFrame == 0 {
+		selfParentFrame = 1
+	}
+	return &FrameProgressStrategy{
+		dagi:          dagi,
+		validators:    validators,
+		getFrameRoots: getFrameRoots,
+		startFrame:    selfParentFrame,
+		fallback:      fallback,
+	}
+}
+
+func (st *FrameProgressStrategy) progressOf(parents hash.Events) frameProgress {
+	for frame := st.startFrame; ; frame++ {
+		var weight pos.Weight
+		counted := make(map[idx.ValidatorID]bool)
+		for _, r := range st.getFrameRoots(frame) {
+			if counted[r.Slot.Validator] {
+				continue
+			}
+			for _, p := range parents {
+				if st.dagi.ForklessCause(p, r.ID) {
+					counted[r.Slot.Validator] = true
+					weight += st.validators.Get(r.Slot.Validator)
+					break
+				}
+			}
+		}
+		if weight < st.validators.Quorum() {
+			return frameProgress{frame, weight}
+		}
+	}
+}
+
+// Choose chooses the hash from the specified options
+func (st *FrameProgressStrategy) Choose(existingParents hash.Events, options hash.Events) int {
+	parents := make(hash.Events, len(existingParents), len(existingParents)+1)
+	copy(parents, existingParents)
+
+	base := st.progressOf(parents)
+	best := base
+	bestI := -1
+	for i, opt := range options {
+		progress := st.progressOf(append(parents, opt))
+		if best.less(progress) {
+			best = progress
+			bestI = i
+		}
+	}
+	if bestI >= 0 {
+		return bestI
+	}
+	if st.fallback != nil {
+		return st.fallback.Choose(existingParents, options)
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package ancestor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+func simulateSeeds(validators *pos.Validators, eventsNum, maxParents, maxDelay, seeds int, strategies simStrategiesFn) simResult {
+	var total simResult
+	for seed := 0; seed < seeds; seed++ {
+		res := simulate(validators, eventsNum, maxParents, maxDelay, int64(seed), strategies)
+		total.Events += res.Events
+		total.Roots += res.Roots
+		total.Frames += res.Frames
+		total.Blocks += res.Blocks
+		total.Parents += res.Parents
+	}
+	return total
+}
+
+func TestFrameProgressStrategy(t *testing.T) {
+	for _, validatorsNum := range []int{5, 20} {
+		t.Run(fmt.Sprintf("%d validators", validatorsNum), func(t *testing.T) {
+			validators := simValidators(validatorsNum)
+
+			quorum := simulateSeeds(validators, 30*validatorsNum, 3, validatorsNum/2, 5, quorumIndexerStrategies)
+			frame := simulateSeeds(validators, 30*validatorsNum, 3, validatorsNum/2, 5, frameProgressStrategies)
+			t.Logf("events per frame: quorum indexer %.2f, frame progress %.2f", quorum.EventsPerFrame(), frame.EventsPerFrame())
+			t.Logf("events per root: quorum indexer %.2f, frame progress %.2f", quorum.EventsPerRoot(), frame.EventsPerRoot())
+
+			require.NotZero(t, frame.Blocks)
+			require.LessOrEqual(t, frame.Parents, 3*frame.Events)
+			// the simulation is deterministic for the seeds, and the strategy doesn't slow the frames down
+			require.Equal(t, frame, simulateSeeds(validators, 30*validatorsNum, 3, validatorsNum/2, 5, frameProgressStrategies))
+			require.GreaterOrEqual(t, frame.Frames, quorum.Frames)
+		})
+	}
+}
+
+func BenchmarkFrameProgressStrategy(b *testing.B) {
+	for _, validatorsNum := range []int{10, 30} {
+		validators := simValidators(validatorsNum)
+		for _, st := range []struct {
+			name       string
+			strategies simStrategiesFn
+		}{
+			{"QuorumIndexer", quorumIndexerStrategies},
+			{"FrameProgress", frameProgressStrategies},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", st.name, validatorsNum), func(b *testing.B) {
+				total := simulateSeeds(validators, 20*validatorsNum, 4, validatorsNum/2, b.N, st.strategies)
+				// time-to-root in the number of emitted events
+				b.ReportMetric(total.EventsPerRoot(), "events/root")
+				b.ReportMetric(total.EventsPerFrame(), "events/frame")
+			})
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ancestor
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+// medianRow is a row of the global matrix, kept sorted by seq in descending order.
+// An update of a single element moves only this element, so it costs O(distance) instead of O(n log n) of a full sort.
+type medianRow struct {
+	order []idx.Validator // observers, sorted by observed seq in descending order
+	pos   []int           // position of an observer in order
+}
+
+func newMedianRow(size idx.Validator) medianRow {
+	r := medianRow{
+		order: make([]idx.Validator, size),
+		pos:   make([]int, size),
+	}
+	for i := range r.order {
+		r.order[i] = idx.Validator(i)
+		r.pos[i] = i
+	}
+	return r
+}
+
+func (r *medianRow) swap(i, j int) {
+	r.order[i], r.order[j] = r.order[j], r.order[i]
+	r.pos[r.order[i]] = i
+	r.pos[r.order[j]] = j
+}
+
+// update restores the order after the seq of the observer has changed
+func (r *medianRow) update(seqs []idx.Event, observer idx.Validator) {
+	i := r.pos[observer]
+	for i > 0 && seqs[r.order[i-1]] < seqs[observer] {
+		r.swap(i-1, i)
+		i--
+	}
+	for i < len(r.order)-1 && seqs[r.order[i+1]] > seqs[observer] {
+		r.swap(i, i+1)
+		i++
+	}
+}
+
+// median returns the highest seq which is observed by the observers with at least stop weight,
+// or 0 if total weight of observers is lower than stop.
+// Observers with zero weight are ignored.
+func (r *medianRow) median(seqs []idx.Event, weights []pos.Weight, stop pos.Weight) idx.Event {
+	var curWeight pos.Weight
+	for _, observer := range r.order {
+		curWeight += weights[observer]
+		if curWeight >= stop {
+			return seqs[observer]
+		}
+	}
+	return 0
+}
@@ -1,15 +1,11 @@
 package ancestor
 
 import (
-	"math"
-	"sort"
-
 	"github.com/Fantom-foundation/lachesis-base/abft/dagidx"
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/inter/pos"
-	"github.com/Fantom-foundation/lachesis-base/utils/wmedian"
 )
 
 type DagIndex interface {
@@ -22,24 +18,37 @@ type QuorumIndexer struct {
 	validators *pos.Validators
 
 	globalMatrix     Matrix
+	medianRows       []medianRow
 	selfParentSeqs   []idx.Event
 	globalMedianSeqs []idx.Event
 	dirty            bool
 	searchStrategy   SearchStrategy
 
+	// cheaters are validators with an observed fork, they are excluded from the medians
+	cheaters      []bool
+	honestWeights []pos.Weight
+
 	diffMetricFn DiffMetricFn
 }
 
 func NewQuorumIndexer(validators *pos.Validators, dagi DagIndex, diffMetricFn DiffMetricFn) *QuorumIndexer {
-	return &QuorumIndexer{
+	h := &QuorumIndexer{
 		globalMatrix:     NewMatrix(validators.Len(), validators.Len()),
+		medianRows:       make([]medianRow, validators.Len()),
 		globalMedianSeqs: make([]idx.Event, validators.Len()),
 		selfParentSeqs:   make([]idx.Event, validators.Len()),
+		cheaters:         make([]bool, validators.Len()),
+		honestWeights:    make([]pos.Weight, validators.Len()),
 		dagi:             dagi,
 		validators:       validators,
 		diffMetricFn:     diffMetricFn,
 		dirty:            true,
 	}
+	for i := range h.medianRows {
+		h.medianRows[i] = newMedianRow(validators.Len())
+	}
+	copy(h.honestWeights, validators.SortedWeights())
+	return h
 }
 
 type Matrix struct {
@@ -67,53 +76,52 @@ func (m Matrix) Clone() Matrix {
 	}
 }
 
-func seqOf(seq dagidx.Seq) idx.Event {
-	if seq.IsForkDetected() {
-		return math.MaxUint32/2 - 1
-	}
-	return seq.Seq()
-}
-
-type weightedSeq struct {
-	seq    idx.Event
-	weight pos.Weight
-}
-
-func (ws weightedSeq) Weight() pos.Weight {
-	return ws.weight
-}
-
+// ProcessEvent updates the global matrix with the observations of the event creator.
+// Only the rows with changed observations are re-sorted, and their medians are recalculated.
+// If the event observes a fork of a validator, the validator is marked as a cheater:
+// its row is excluded from the metric, and its observations are excluded from the medians.
 func (h *QuorumIndexer) ProcessEvent(event dag.Event, selfEvent bool) {
 	vecClock := h.dagi.GetMergedHighestBefore(event.ID())
 	creatorIdx := h.validators.GetIdx(event.Creator())
+	newCheaters := false
 	// update global matrix
 	for validatorIdx := idx.Validator(0); validatorIdx < h.validators.Len(); validatorIdx++ {
-		seq := seqOf(vecClock.Get(validatorIdx))
-		h.globalMatrix.Row(validatorIdx)[creatorIdx] = seq
+		seq := vecClock.Get(validatorIdx)
+		if seq.IsForkDetected() {
+			if !h.cheaters[validatorIdx] {
+				h.cheaters[validatorIdx] = true
+				h.honestWeights[validatorIdx] = 0
+				newCheaters = true
+			}
+			continue
+		}
 		if selfEvent {
-			h.selfParentSeqs[validatorIdx] = seq
+			h.selfParentSeqs[validatorIdx] = seq.Seq()
+		}
+		row := h.globalMatrix.Row(validatorIdx)
+		if row[creatorIdx] == seq.Seq() {
+			continue
+		}
+		row[creatorIdx] = seq.Seq()
+		h.medianRows[validatorIdx].update(row, creatorIdx)
+		if !newCheaters {
+			h.recalcMedian(validatorIdx)
+		}
+	}
+	if newCheaters {
+		// weights of observers have changed, so all the medians are affected
+		for validatorIdx := idx.Validator(0); validatorIdx < h.validators.Len(); validatorIdx++ {
+			h.recalcMedian(validatorIdx)
 		}
 	}
 	h.dirty = true
 }
 
+func (h *QuorumIndexer) recalcMedian(validatorIdx idx.Validator) {
+	h.globalMedianSeqs[validatorIdx] = h.medianRows[validatorIdx].median(h.globalMatrix.Row(validatorIdx), h.honestWeights, h.validators.Quorum())
+}
+
 func (h *QuorumIndexer) recacheState() {
-	// update median seqs
-	for validatorIdx := idx.Validator(0); validatorIdx < h.validators.Len(); validatorIdx++ {
-		pairs := make([]wmedian.WeightedValue, h.validators.Len())
-		for i := range pairs {
-			pairs[i] = weightedSeq{
-				seq:    h.globalMatrix.Row(validatorIdx)[i],
-				weight: h.validators.GetWeightByIdx(idx.Validator(i)),
-			}
-		}
-		sort.Slice(pairs, func(i, j int) bool {
-			a, b := pairs[i].(weightedSeq), pairs[j].(weightedSeq)
-			return a.seq > b.seq
-		})
-		median := wmedian.Of(pairs, h.validators.Quorum())
-		h.globalMedianSeqs[validatorIdx] = median.(weightedSeq).seq
-	}
 	// invalidate search strategy cache
 	cache := NewMetricFnCache(h.GetMetricOf, 128)
 	h.searchStrategy = NewMetricStrategy(cache.GetMetricOf)
@@ -127,7 +135,11 @@ func (h *QuorumIndexer) GetMetricOf(id hash.Event) Metric {
 	vecClock := h.dagi.GetMergedHighestBefore(id)
 	var metric Metric
 	for validatorIdx := idx.Validator(0); validatorIdx < h.validators.Len(); validatorIdx++ {
-		update := seqOf(vecClock.Get(validatorIdx))
+		seq := vecClock.Get(validatorIdx)
+		if h.cheaters[validatorIdx] || seq.IsForkDetected() {
+			continue
+		}
+		update := seq.Seq()
 		current := h.selfParentSeqs[validatorIdx]
 		median := h.globalMedianSeqs[validatorIdx]
 		metric += h.diffMetricFn(median, current, update, validatorIdx)
@@ -143,9 +155,6 @@ func (h *QuorumIndexer) SearchStrategy() SearchStrategy {
 }
 
 func (h *QuorumIndexer) GetGlobalMedianSeqs() []idx.Event {
-	if h.dirty {
-		h.recacheState()
-	}
 	return h.globalMedianSeqs
 }
 
@@ -156,3 +165,8 @@ func (h *QuorumIndexer) GetGlobalMatrix() Matrix {
 func (h *QuorumIndexer) GetSelfParentSeqs() []idx.Event {
 	return h.selfParentSeqs
 }
+
+// IsCheater returns true if a fork of the validator was observed by one of the processed events
+func (h *QuorumIndexer) IsCheater(validatorIdx idx.Validator) bool {
+	return h.cheaters[validatorIdx]
+}
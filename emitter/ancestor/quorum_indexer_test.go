@@ -1,13 +1,17 @@
 package ancestor
 
 import (
+	"fmt"
+	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/Fantom-foundation/lachesis-base/abft/dagidx"
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
@@ -217,3 +221,157 @@ func parentsToString(pp hash.Events) string {
 
 	return res.String()
 }
+
+// recalcMedians calculates the medians from scratch by sorting each row of the global matrix
+func recalcMedians(h *QuorumIndexer) []idx.Event {
+	medians := make([]idx.Event, h.validators.Len())
+	for validatorIdx := range medians {
+		row := h.GetGlobalMatrix().Row(idx.Validator(validatorIdx))
+		observers := make([]int, len(row))
+		for i := range observers {
+			observers[i] = i
+		}
+		sort.Slice(observers, func(i, j int) bool {
+			return row[observers[i]] > row[observers[j]]
+		})
+		var weight pos.Weight
+		for _, observer := range observers {
+			if h.IsCheater(idx.Validator(observer)) {
+				continue
+			}
+			weight += h.validators.GetWeightByIdx(idx.Validator(observer))
+			if weight >= h.validators.Quorum() {
+				medians[validatorIdx] = row[observer]
+				break
+			}
+		}
+	}
+	return medians
+}
+
+func TestQuorumIndexer_Medians(t *testing.T) {
+	for _, cheatersNum := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("%d cheaters", cheatersNum), func(t *testing.T) {
+			require := require.New(t)
+
+			nodes := tdag.GenNodes(10)
+			cheaters := nodes[:cheatersNum]
+			validators := pos.EqualWeightValidators(nodes, 1)
+
+			events := make(map[hash.Event]dag.Event)
+			vecClock := vecfc.NewIndex(func(err error) { panic(err) }, vecfc.LiteConfig())
+			vecClock.Reset(validators, memorydb.New(), func(id hash.Event) dag.Event {
+				return events[id]
+			})
+			h := NewQuorumIndexer(validators, &adapters.VectorToDagIndexer{Index: vecClock}, func(median, current, update idx.Event, validatorIdx idx.Validator) Metric {
+				return Metric(update)
+			})
+
+			_ = tdag.ForEachRandFork(nodes, cheaters, 50, 4, 10, rand.New(rand.NewSource(int64(cheatersNum))), tdag.ForEachEvent{
+				Process: func(e dag.Event, name string) {
+					if events[e.ID()] != nil {
+						return
+					}
+					events[e.ID()] = e
+					require.NoError(vecClock.Add(e))
+					h.ProcessEvent(e, e.Creator() == nodes[len(nodes)-1])
+					require.Equal(recalcMedians(h), h.GetGlobalMedianSeqs())
+				},
+			})
+
+			for _, cheater := range cheaters {
+				require.True(h.IsCheater(validators.GetIdx(cheater)))
+			}
+			for _, honest := range nodes[cheatersNum:] {
+				require.False(h.IsCheater(validators.GetIdx(honest)))
+			}
+		})
+	}
+}
+
+type fakeSeq idx.Event
+
+func (s fakeSeq) Seq() idx.Event {
+	return idx.Event(s)
+}
+
+func (s fakeSeq) IsForkDetected() bool {
+	return false
+}
+
+type fakeHighestBefore []idx.Event
+
+func (hb fakeHighestBefore) Size() int {
+	return len(hb)
+}
+
+func (hb fakeHighestBefore) Get(i idx.Validator) dagidx.Seq {
+	return fakeSeq(hb[i])
+}
+
+// fakeVectorClock is a vector clock of a DAG, where each event observes a random subset of the latest events
+type fakeVectorClock map[hash.Event]fakeHighestBefore
+
+func (vc fakeVectorClock) GetMergedHighestBefore(id hash.Event) dagidx.HighestBeforeSeq {
+	return vc[id]
+}
+
+func BenchmarkQuorumIndexer(b *testing.B) {
+	for _, validatorsNum := range []int{10, 100, 300, 1000} {
+		validators := pos.EqualWeightValidators(tdag.GenNodes(validatorsNum), 1)
+		r := rand.New(rand.NewSource(0))
+
+		// generate the events, each of which observes a few new events of other validators
+		vecClock := fakeVectorClock{}
+		last := make([]fakeHighestBefore, validatorsNum)
+		for i := range last {
+			last[i] = make(fakeHighestBefore, validatorsNum)
+		}
+		events := make(dag.Events, 10*validatorsNum)
+		for i := range events {
+			creatorIdx := r.Intn(validatorsNum)
+			hb := make(fakeHighestBefore, validatorsNum)
+			copy(hb, last[creatorIdx])
+			for p := 0; p < 3; p++ {
+				for v, seq := range last[r.Intn(validatorsNum)] {
+					if hb[v] < seq {
+						hb[v] = seq
+					}
+				}
+			}
+			hb[creatorIdx]++
+			last[creatorIdx] = hb
+
+			e := &tdag.TestEvent{}
+			e.SetCreator(validators.GetID(idx.Validator(creatorIdx)))
+			e.SetSeq(hb[creatorIdx])
+			var id [24]byte
+			copy(id[:], hash.FakeEvent().Bytes())
+			e.SetID(id)
+			vecClock[e.ID()] = hb
+			events[i] = e
+		}
+		selfID := validators.GetID(0)
+
+		b.Run(fmt.Sprintf("%d validators", validatorsNum), func(b *testing.B) {
+			b.ReportAllocs()
+			var h *QuorumIndexer
+			for i := 0; i < b.N; i++ {
+				if i%len(events) == 0 {
+					b.StopTimer()
+					h = NewQuorumIndexer(validators, vecClock, func(median, current, update idx.Event, validatorIdx idx.Validator) Metric {
+						if update <= median || update <= current {
+							return 0
+						}
+						return Metric(update - median)
+					})
+					b.StartTimer()
+				}
+				e := events[i%len(events)]
+				h.ProcessEvent(e, e.Creator() == selfID)
+				_ = h.GetGlobalMedianSeqs()
+				_ = h.GetMetricOf(e.ID())
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package ancestor
+
+import (
+	"crypto/sha256"
+	"math/rand"
+
+	"github.com/Fantom-foundation/lachesis-base/abft"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/lachesis"
+	"github.com/Fantom-foundation/lachesis-base/utils/adapters"
+	"github.com/Fantom-foundation/lachesis-base/vecfc"
+)
+
+// simStrategiesFn returns the parents search strategies of the validator for the next event
+type simStrategiesFn func(sim *simulation, v *simValidator, selfParent dag.Event) []SearchStrategy
+
+// simValidator is a view of the DAG by a validator, which receives the events with a delay
+type simValidator struct {
+	id            idx.ValidatorID
+	heads         hash.EventsSet
+	last          dag.Event
+	quorumIndexer *QuorumIndexer
+	pending       dag.Events
+	deliverAt     map[hash.Event]int
+}
+
+// simulation is a network of validators, which emit events in a random order and receive events of
+// other validators with a random delay.
+// The consensus is calculated by a single abft instance, which processes the events in the emission order.
+type simulation struct {
+	r          *rand.Rand
+	validators *pos.Validators
+	maxParents int
+	maxDelay   int
+
+	events     map[hash.Event]dag.Event
+	emittedAt  map[hash.Event]int
+	processing dag.Event
+	store      *abft.Store
+	lch        *abft.IndexedLachesis
+	dagIndex   *adapters.VectorToDagIndexer
+
+	nodes []*simValidator
+	step  int
+	// onConnect is called after an event is added to the validator's view
+	onConnect func(v *simValidator, e dag.Event)
+
+	res simResult
+}
+
+// simResult is the simulation statistics
+type simResult struct {
+	Events  int
+	Roots   int
+	Frames  idx.Frame
+	Blocks  int
+	Parents int
+	// Rounds is the total number of frames between the decided Atropos and the event which decided it
+	Rounds int
+	// Latency is the total number of events emitted after the decided Atropos until it's decided
+	Latency int
+}
+
+// FramesPerEvent is the average frame progress per emitted event
+func (r simResult) FramesPerEvent() float64 {
+	return float64(r.Frames) / float64(r.Events)
+}
+
+// ElectionRounds is the average number of frames to decide an Atropos
+func (r simResult) ElectionRounds() float64 {
+	return float64(r.Rounds) / float64(r.Blocks)
+}
+
+// AtroposLatency is the average number of events emitted after an Atropos until it's decided
+func (r simResult) AtroposLatency() float64 {
+	return float64(r.Latency) / float64(r.Blocks)
+}
+
+// ParentsPerEvent is the average number of parents, including the self-parent
+func (r simResult) ParentsPerEvent() float64 {
+	return float64(r.Parents) / float64(r.Events)
+}
+
+// EventsPerFrame is the average number of events per frame
+func (r simResult) EventsPerFrame() float64 {
+	return float64(r.Events) / float64(r.Frames)
+}
+
+// EventsPerRoot is the average number of events a validator emits before it emits a root
+func (r simResult) EventsPerRoot() float64 {
+	return float64(r.Events) / float64(r.Roots)
+}
+
+// simValidators returns validators with fixed IDs, unlike tdag.GenNodes,
+// so the simulation is deterministic for a seed
+func simValidators(num int) *pos.Validators {
+	ids := make([]idx.ValidatorID, num)
+	for i := range ids {
+		ids[i] = idx.ValidatorID(i + 1)
+	}
+	return pos.EqualWeightValidators(ids, 1)
+}
+
+func newSimulation(validators *pos.Validators, maxParents, maxDelay int, r *rand.Rand) *simulation {
+	crit := func(err error) {
+		panic(err)
+	}
+	s := &simulation{
+		r:          r,
+		validators: validators,
+		maxParents: maxParents,
+		maxDelay:   maxDelay,
+		events:     make(map[hash.Event]dag.Event),
+		emittedAt:  make(map[hash.Event]int),
+		store:      abft.NewMemStore(),
+	}
+	err := s.store.ApplyGenesis(&abft.Genesis{
+		Validators: validators,
+		Epoch:      abft.FirstEpoch,
+	})
+	if err != nil {
+		panic(err)
+	}
+	s.dagIndex = &adapters.VectorToDagIndexer{Index: vecfc.NewIndex(crit, vecfc.LiteConfig())}
+	s.lch = abft.NewIndexedLachesis(s.store, s, s.dagIndex, crit, abft.LiteConfig())
+	err = s.lch.Bootstrap(lachesis.ConsensusCallbacks{
+		BeginBlock: func(block *lachesis.Block) lachesis.BlockCallbacks {
+			s.res.Blocks++
+			s.res.Rounds += int(s.processing.Frame() - s.events[block.Atropos].Frame())
+			s.res.Latency += s.emittedAt[s.processing.ID()] - s.emittedAt[block.Atropos]
+			return lachesis.BlockCallbacks{}
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, id := range validators.SortedIDs() {
+		s.nodes = append(s.nodes, &simValidator{
+			id:            id,
+			heads:         hash.EventsSet{},
+			quorumIndexer: NewQuorumIndexer(validators, s.dagIndex, newSimDiffMetricFn(validators)),
+			deliverAt:     make(map[hash.Event]int),
+		})
+	}
+	return s
+}
+
+func newSimDiffMetricFn(validators *pos.Validators) DiffMetricFn {
+	capFn := func(diff idx.Event, weight pos.Weight) Metric {
+		if diff > 2 {
+			return Metric(2 * weight)
+		}
+		return Metric(diff) * Metric(weight)
+	}
+	return func(median, current, update idx.Event, validatorIdx idx.Validator) Metric {
+		if update <= median || update <= current {
+			return 0
+		}
+		if median < current {
+			return capFn(update-median, validators.GetWeightByIdx(validatorIdx)) - capFn(current-median, validators.GetWeightByIdx(validatorIdx))
+		}
+		return capFn(update-median, validators.GetWeightByIdx(validatorIdx))
+	}
+}
+
+func (s *simulation) GetEvent(id hash.Event) dag.Event {
+	return s.events[id]
+}
+
+func (s *simulation) HasEvent(id hash.Event) bool {
+	_, ok := s.events[id]
+	return ok
+}
+
+// connect adds the event to the validator's view
+func (s *simulation) connect(v *simValidator, e dag.Event) {
+	for _, p := range e.Parents() {
+		v.heads.Erase(p)
+	}
+	v.heads.Add(e.ID())
+	v.quorumIndexer.ProcessEvent(e, e.Creator() == v.id)
+	if s.onConnect != nil {
+		s.onConnect(v, e)
+	}
+}
+
+// deliver connects the received events to the validator's view
+func (s *simulation) deliver(v *simValidator) {
+	pending := v.pending[:0]
+	for _, e := range v.pending {
+		if v.deliverAt[e.ID()] > s.step {
+			pending = append(pending, e)
+			continue
+		}
+		s.connect(v, e)
+	}
+	v.pending = pending
+}
+
+// chooseParents is ChooseParents, which shuffles the options by the simulation seed instead of the map order,
+// so the simulation is deterministic for a seed
+func (s *simulation) chooseParents(existingParents hash.Events, options hash.Events, strategies []SearchStrategy) hash.Events {
+	optionsSet := options.Set()
+	parents := make(hash.Events, 0, len(strategies)+len(existingParents))
+	parents = append(parents, existingParents...)
+	for _, p := range existingParents {
+		optionsSet.Erase(p)
+	}
+
+	for i := 0; i < len(strategies) && len(optionsSet) > 0; i++ {
+		curOptions := optionsSet.Slice()
+		hash.OrderedEvents(curOptions).ByEpochAndLamport()
+		s.r.Shuffle(len(curOptions), func(i, j int) {
+			curOptions[i], curOptions[j] = curOptions[j], curOptions[i]
+		})
+		best := strategies[i].Choose(parents, curOptions)
+		parents = append(parents, curOptions[best])
+		optionsSet.Erase(curOptions[best])
+	}
+
+	return parents
+}
+
+// build creates a new event of the validator with parents chosen from the options, and processes it
+func (s *simulation) build(v *simValidator, options hash.Events, strategiesFn simStrategiesFn) dag.Event {
+	var existing hash.Events
+	if v.last != nil {
+		existing = append(existing, v.last.ID())
+	}
+	parents := s.chooseParents(existing, options, strategiesFn(s, v, v.last))
+
+	e := &tdag.TestEvent{}
+	e.SetEpoch(abft.FirstEpoch)
+	e.SetCreator(v.id)
+	e.SetParents(parents)
+	e.SetSeq(1)
+	if v.last != nil {
+		e.SetSeq(v.last.Seq() + 1)
+	}
+	var lamport idx.Lamport
+	for _, p := range parents {
+		if lamport < s.events[p].Lamport() {
+			lamport = s.events[p].Lamport()
+		}
+	}
+	e.SetLamport(lamport + 1)
+	if err := s.lch.Build(e); err != nil {
+		panic(err)
+	}
+	hasher := sha256.New()
+	hasher.Write(e.Bytes())
+	var id [24]byte
+	copy(id[:], hasher.Sum(nil)[:24])
+	e.SetID(id)
+
+	s.events[e.ID()] = e
+	s.emittedAt[e.ID()] = s.res.Events
+	s.processing = e
+	if err := s.lch.Process(e); err != nil {
+		panic(err)
+	}
+
+	s.res.Events++
+	s.res.Parents += len(parents)
+	if v.last == nil || v.last.Frame() != e.Frame() {
+		s.res.Roots++
+	}
+	if s.res.Frames < e.Frame() {
+		s.res.Frames = e.Frame()
+	}
+	v.last = e
+	return e
+}
+
+// emit creates a new event of the validator, and sends it to other validators
+func (s *simulation) emit(v *simValidator, strategiesFn simStrategiesFn) dag.Event {
+	s.deliver(v)
+	e := s.build(v, v.heads.Slice(), strategiesFn)
+
+	// send the event, preserving the parents-first order
+	for _, n := range s.nodes {
+		at := s.step
+		if n != v && s.maxDelay > 0 {
+			at += 1 + s.r.Intn(s.maxDelay)
+		}
+		for _, p := range e.Parents() {
+			if n.deliverAt[p] > at {
+				at = n.deliverAt[p]
+			}
+		}
+		n.deliverAt[e.ID()] = at
+		n.pending = append(n.pending, e)
+	}
+	s.deliver(v)
+	return e
+}
+
+// simulate emits the events by randomly chosen validators, one event per step
+func simulate(validators *pos.Validators, eventsNum, maxParents, maxDelay int, seed int64, strategiesFn simStrategiesFn) simResult {
+	s := newSimulation(validators, maxParents, maxDelay, rand.New(rand.NewSource(seed)))
+	for s.step = 0; s.step < eventsNum; s.step++ {
+		s.emit(s.nodes[s.r.Intn(len(s.nodes))], strategiesFn)
+	}
+	return s.res
+}
+
+func quorumIndexerStrategies(sim *simulation, v *simValidator, _ dag.Event) []SearchStrategy {
+	strategies := make([]SearchStrategy, sim.maxParents-1)
+	for i := range strategies {
+		strategies[i] = v.quorumIndexer.SearchStrategy()
+	}
+	return strategies
+}
+
+func frameProgressStrategies(sim *simulation, v *simValidator, selfParent dag.Event) []SearchStrategy {
+	var selfParentFrame idx.Frame
+	if selfParent != nil {
+		selfParentFrame = selfParent.Frame()
+	}
+	st := NewFrameProgressStrategy(sim.validators, sim.dagIndex, sim.store.GetFrameRoots, selfParentFrame, v.quorumIndexer.SearchStrategy())
+	strategies := make([]SearchStrategy, sim.maxParents-1)
+	for i := range strategies {
+		strategies[i] = st
+	}
+	return strategies
+}
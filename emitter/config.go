@@ -0,0 +1,83 @@
+package emitter
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+var (
+	ErrInvalidEmitInterval = errors.New("MinEmitInterval must be positive")
+)
+
+type Config struct {
+	// Validator is the ID of the validator which events are emitted
+	Validator idx.ValidatorID
+
+	// MinEmitInterval is the minimum interval between self-events, and the period of the emission loop
+	MinEmitInterval time.Duration
+	// MaxEmitInterval is the interval after which a self-event is emitted even if it makes no progress
+	MaxEmitInterval time.Duration
+	// PayloadBacklog is the number of pending payload items, at which events are emitted every MinEmitInterval
+	PayloadBacklog int
+
+	// MaxParents is the maximum number of parents, including the self-parent
+	MaxParents int
+
+	// DoublesignProtection is the threshold of doublesign.SyncedToEmit
+	DoublesignProtection time.Duration
+}
+
+// DefaultConfig returns default emitter config
+func DefaultConfig(validator idx.ValidatorID) Config {
+	return Config{
+		Validator:            validator,
+		MinEmitInterval:      200 * time.Millisecond,
+		MaxEmitInterval:      10 * time.Minute,
+		PayloadBacklog:       100,
+		MaxParents:           10,
+		DoublesignProtection: 27 * time.Minute,
+	}
+}
+
+// Validate checks the config values, which would break the emission loop
+func (c Config) Validate() error {
+	if c.MinEmitInterval <= 0 {
+		return ErrInvalidEmitInterval
+	}
+	return nil
+}
+
+// LiteConfig returns emitter config for tests
+func LiteConfig(validator idx.ValidatorID) Config {
+	cfg := DefaultConfig(validator)
+	cfg.MinEmitInterval = time.Millisecond
+	cfg.MaxEmitInterval = time.Second
+	cfg.MaxParents = 3
+	cfg.DoublesignProtection = 0
+	return cfg
+}
+
+// GateConfig is the limits of the node health signals, see NewHealthGate
+type GateConfig struct {
+	// MaxBuffered is the maximum number and size of buffered events, which aren't connected yet
+	MaxBuffered dag.Metric
+	// MaxFetcherBacklog is the maximum number of events, which are waiting to be fetched
+	MaxFetcherBacklog int
+	// MaxFrameLag is the maximum lag of the last decided frame behind peers
+	MaxFrameLag idx.Frame
+}
+
+// DefaultGateConfig returns default limits of the node health signals
+func DefaultGateConfig() GateConfig {
+	return GateConfig{
+		MaxBuffered: dag.Metric{
+			Num:  1500,
+			Size: 5 * 1024 * 1024,
+		},
+		MaxFetcherBacklog: 10000,
+		MaxFrameLag:       10,
+	}
+}
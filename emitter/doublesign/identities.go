@@ -0,0 +1,72 @@
+package doublesign
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+// IdentityStatus is the part of SyncStatus which is specific to a validator ID
+type IdentityStatus struct {
+	BecameValidator           time.Time
+	ExternalSelfEventCreated  time.Time
+	ExternalSelfEventDetected time.Time
+}
+
+// WithIdentity returns a copy of the node status with the fields of the specified validator ID
+func (s SyncStatus) WithIdentity(id IdentityStatus) SyncStatus {
+	s.BecameValidator = id.BecameValidator
+	s.ExternalSelfEventCreated = id.ExternalSelfEventCreated
+	s.ExternalSelfEventDetected = id.ExternalSelfEventDetected
+	return s
+}
+
+// Identities tracks IdentityStatus of several local validator IDs,
+// so that an external self-event of one validator doesn't affect emission of another validator.
+// Identities is safe for concurrent use.
+type Identities struct {
+	mu       sync.RWMutex
+	statuses map[idx.ValidatorID]IdentityStatus
+}
+
+// NewIdentities creates Identities instance.
+func NewIdentities() *Identities {
+	return &Identities{
+		statuses: make(map[idx.ValidatorID]IdentityStatus),
+	}
+}
+
+// Get returns the status of the validator ID
+func (ii *Identities) Get(validator idx.ValidatorID) IdentityStatus {
+	ii.mu.RLock()
+	defer ii.mu.RUnlock()
+
+	return ii.statuses[validator]
+}
+
+// SetBecameValidator should be called when the validator ID joins the validators group
+func (ii *Identities) SetBecameValidator(validator idx.ValidatorID, t time.Time) {
+	ii.mu.Lock()
+	defer ii.mu.Unlock()
+
+	s := ii.statuses[validator]
+	s.BecameValidator = t
+	ii.statuses[validator] = s
+}
+
+// OnExternalSelfEvent should be called after downloading an event of the validator ID,
+// which wasn't created on this instance
+func (ii *Identities) OnExternalSelfEvent(validator idx.ValidatorID, created, detected time.Time) {
+	ii.mu.Lock()
+	defer ii.mu.Unlock()
+
+	s := ii.statuses[validator]
+	if s.ExternalSelfEventCreated.Before(created) {
+		s.ExternalSelfEventCreated = created
+	}
+	if s.ExternalSelfEventDetected.Before(detected) {
+		s.ExternalSelfEventDetected = detected
+	}
+	ii.statuses[validator] = s
+}
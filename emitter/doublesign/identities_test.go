@@ -0,0 +1,42 @@
+package doublesign
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentities(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(1000, 0)
+	threshold := time.Minute
+	s := SyncStatus{
+		PeersNum:  1,
+		Now:       now,
+		Startup:   now.Add(-time.Hour),
+		P2PSynced: now.Add(-time.Hour),
+	}
+
+	ii := NewIdentities()
+	ii.SetBecameValidator(1, now.Add(-time.Hour))
+	ii.SetBecameValidator(2, now.Add(-time.Hour))
+	ii.OnExternalSelfEvent(1, now.Add(-time.Second), now)
+	// older events don't reset the status
+	ii.OnExternalSelfEvent(1, now.Add(-time.Hour), now.Add(-time.Hour))
+
+	wait, err := SyncedToEmit(s.WithIdentity(ii.Get(1)), threshold)
+	require.Equal(ErrSelfEventsOngoing, err)
+	require.Equal(threshold, wait)
+	require.True(DetectParallelInstance(s.WithIdentity(ii.Get(1)), threshold))
+
+	// another identity isn't affected
+	_, err = SyncedToEmit(s.WithIdentity(ii.Get(2)), threshold)
+	require.NoError(err)
+	require.False(DetectParallelInstance(s.WithIdentity(ii.Get(2)), threshold))
+
+	ii.SetBecameValidator(2, now)
+	_, err = SyncedToEmit(s.WithIdentity(ii.Get(2)), threshold)
+	require.Equal(ErrJustBecameValidator, err)
+}
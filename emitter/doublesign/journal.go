@@ -0,0 +1,128 @@
+package doublesign
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+)
+
+var (
+	ErrConflictingEmission = errors.New("event conflicts with a previously emitted self-event")
+)
+
+// JournalRecord is the last emitted self-event of a validator
+type JournalRecord struct {
+	Epoch   idx.Epoch
+	Seq     idx.Event
+	Lamport idx.Lamport
+	ID      hash.Event
+}
+
+// JournalStore is a DB of Journal, which is able to write the records to disk synchronously
+type JournalStore interface {
+	kvdb.Store
+	kvdb.SyncWriter
+}
+
+// Journal is a durable record of the last emitted self-events.
+// Unlike the time-based heuristics, it protects against a re-emission of a used seq after the node's DB
+// is restored from an older backup, as long as the journal DB isn't restored along with it.
+// Records are written to disk synchronously.
+// Journal is safe for concurrent use.
+type Journal struct {
+	db JournalStore
+
+	mu    sync.Mutex
+	cache map[idx.ValidatorID]*JournalRecord
+}
+
+// NewJournal creates Journal instance.
+func NewJournal(db JournalStore) *Journal {
+	return &Journal{
+		db:    db,
+		cache: make(map[idx.ValidatorID]*JournalRecord),
+	}
+}
+
+// Get returns the last emitted self-event of the validator, or nil if there's no record.
+func (j *Journal) Get(validator idx.ValidatorID) (*JournalRecord, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.get(validator)
+}
+
+func (j *Journal) get(validator idx.ValidatorID) (*JournalRecord, error) {
+	if r, ok := j.cache[validator]; ok {
+		return r, nil
+	}
+	b, err := j.db.Get(validator.Bytes())
+	if err != nil || b == nil {
+		return nil, err
+	}
+	r := &JournalRecord{}
+	if err := rlp.DecodeBytes(b, r); err != nil {
+		return nil, err
+	}
+	j.cache[validator] = r
+	return r, nil
+}
+
+// Check returns ErrConflictingEmission if the self-event conflicts with the last emitted self-event.
+// The event doesn't conflict only if it's already recorded, or if it's from a later epoch,
+// or if it's the next event after the recorded event.
+func (j *Journal) Check(e dag.Event) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.check(e)
+}
+
+func (j *Journal) check(e dag.Event) error {
+	last, err := j.get(e.Creator())
+	if err != nil || last == nil {
+		return err
+	}
+	if last.ID == e.ID() || e.Epoch() > last.Epoch {
+		return nil
+	}
+	if e.Epoch() < last.Epoch || e.Seq() <= last.Seq || e.Lamport() <= last.Lamport {
+		return ErrConflictingEmission
+	}
+	if e.Seq() == last.Seq+1 && (e.SelfParent() == nil || *e.SelfParent() != last.ID) {
+		return ErrConflictingEmission
+	}
+	return nil
+}
+
+// Record checks the self-event and writes it as the last emitted self-event.
+// It should be called before the event is connected and broadcast.
+func (j *Journal) Record(e dag.Event) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.check(e); err != nil {
+		return err
+	}
+	r := &JournalRecord{
+		Epoch:   e.Epoch(),
+		Seq:     e.Seq(),
+		Lamport: e.Lamport(),
+		ID:      e.ID(),
+	}
+	b, err := rlp.EncodeToBytes(r)
+	if err != nil {
+		return err
+	}
+	if err := j.db.SyncPut(e.Creator().Bytes(), b); err != nil {
+		return err
+	}
+	j.cache[e.Creator()] = r
+	return nil
+}
@@ -0,0 +1,95 @@
+package doublesign
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/leveldb"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+)
+
+func fakeSelfEvent(epoch idx.Epoch, seq idx.Event, lamport idx.Lamport, selfParent *hash.Event) dag.Event {
+	e := &tdag.TestEvent{}
+	e.SetCreator(1)
+	e.SetEpoch(epoch)
+	e.SetSeq(seq)
+	e.SetLamport(lamport)
+	if selfParent != nil {
+		e.SetParents(hash.Events{*selfParent})
+	}
+	var id [24]byte
+	copy(id[:], hash.FakeEvent().Bytes())
+	e.SetID(id)
+	return e
+}
+
+func TestJournal(t *testing.T) {
+	t.Run("memorydb", func(t *testing.T) {
+		testJournal(t, memorydb.New())
+	})
+	t.Run("leveldb", func(t *testing.T) {
+		db, err := leveldb.New(t.TempDir(), 16, 0, nil, nil)
+		require.NoError(t, err)
+		defer db.Close()
+		testJournal(t, db)
+	})
+}
+
+func testJournal(t *testing.T, db JournalStore) {
+	require := require.New(t)
+
+	j := NewJournal(db)
+	r, err := j.Get(1)
+	require.NoError(err)
+	require.Nil(r)
+
+	e1 := fakeSelfEvent(2, 1, 1, nil)
+	require.NoError(j.Check(e1))
+	require.NoError(j.Record(e1))
+	e1ID := e1.ID()
+	e2 := fakeSelfEvent(2, 2, 5, &e1ID)
+	require.NoError(j.Record(e2))
+	// already recorded event isn't a conflict
+	require.NoError(j.Record(e2))
+
+	// journal survives a restart
+	j = NewJournal(db)
+	r, err = j.Get(1)
+	require.NoError(err)
+	require.Equal(&JournalRecord{
+		Epoch:   2,
+		Seq:     2,
+		Lamport: 5,
+		ID:      e2.ID(),
+	}, r)
+	r, err = j.Get(2)
+	require.NoError(err)
+	require.Nil(r)
+
+	// seq is already used
+	require.Equal(ErrConflictingEmission, j.Check(fakeSelfEvent(2, 2, 6, &e1ID)))
+	require.Equal(ErrConflictingEmission, j.Check(fakeSelfEvent(2, 1, 6, nil)))
+	// previous epoch
+	require.Equal(ErrConflictingEmission, j.Check(fakeSelfEvent(1, 3, 6, nil)))
+	// next seq must have the recorded self-parent
+	require.Equal(ErrConflictingEmission, j.Check(fakeSelfEvent(2, 3, 6, &e1ID)))
+	require.Equal(ErrConflictingEmission, j.Check(fakeSelfEvent(2, 3, 6, nil)))
+	// lamport must grow
+	e2ID := e2.ID()
+	require.Equal(ErrConflictingEmission, j.Check(fakeSelfEvent(2, 3, 5, &e2ID)))
+	require.Equal(ErrConflictingEmission, j.Record(fakeSelfEvent(2, 3, 5, &e2ID)))
+
+	require.NoError(j.Check(fakeSelfEvent(2, 3, 6, &e2ID)))
+	// a gap is allowed, because the journal may be behind if the recording has failed
+	require.NoError(j.Check(fakeSelfEvent(2, 5, 8, nil)))
+	// next epoch
+	require.NoError(j.Check(fakeSelfEvent(3, 1, 1, nil)))
+	e3 := fakeSelfEvent(3, 1, 1, nil)
+	require.NoError(j.Record(e3))
+	require.Equal(ErrConflictingEmission, j.Check(fakeSelfEvent(2, 3, 6, &e2ID)))
+}
@@ -1,6 +1,12 @@
 package doublesign
 
-import "time"
+import (
+	"errors"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+)
 
 // DetectParallelInstance should be called after downloading a self-event which wasn't created on this instance
 // Returns true if a parallel instance is likely be running
@@ -10,3 +16,133 @@ func DetectParallelInstance(s SyncStatus, threshold time.Duration) bool {
 	}
 	return s.Since(s.ExternalSelfEventCreated) < threshold
 }
+
+var (
+	ErrConflictingSelfEvent       = errors.New("self-event conflicts with another self-event")
+	ErrSelfEventAhead             = errors.New("self-event is ahead of the last emitted self-event")
+	ErrSelfEventCreatedAfterStart = errors.New("self-event was created after the startup")
+	ErrRecentSelfEvent            = errors.New("self-event was created recently")
+)
+
+// Confidence is a level of certainty that a parallel instance is running
+type Confidence int
+
+const (
+	NoParallelInstance Confidence = iota
+	// PossibleParallelInstance means that an external self-event was created recently, e.g. before a restart
+	PossibleParallelInstance
+	// LikelyParallelInstance means that an external self-event was created during this run,
+	// or that it's ahead of the last emitted self-event
+	LikelyParallelInstance
+	// CertainParallelInstance means that an external self-event forms a fork with another self-event
+	CertainParallelInstance
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case NoParallelInstance:
+		return "none"
+	case PossibleParallelInstance:
+		return "possible"
+	case LikelyParallelInstance:
+		return "likely"
+	case CertainParallelInstance:
+		return "certain"
+	}
+	return "unknown"
+}
+
+// ExternalSelfEvent is a self-event which wasn't created on this instance
+type ExternalSelfEvent struct {
+	Event dag.Event
+	// Created is the creation time of the event, as it's reported by the peer
+	Created time.Time
+}
+
+// Evidence is an external self-event which indicates a parallel instance
+type Evidence struct {
+	ID     hash.Event
+	Reason error
+}
+
+// ParallelInstanceVerdict is a result of InspectParallelInstance
+type ParallelInstanceVerdict struct {
+	Confidence Confidence
+	Evidence   []Evidence
+	// Wait is the recommended duration to wait before emitting
+	Wait time.Duration
+}
+
+// Halt returns true if the emission should be halted until the operator intervenes
+func (v *ParallelInstanceVerdict) Halt() bool {
+	return v.Confidence == CertainParallelInstance
+}
+
+func (v *ParallelInstanceVerdict) add(id hash.Event, reason error, confidence Confidence, wait time.Duration) {
+	v.Evidence = append(v.Evidence, Evidence{id, reason})
+	if v.Confidence < confidence {
+		v.Confidence = confidence
+	}
+	if v.Wait < wait {
+		v.Wait = wait
+	}
+}
+
+// remaining returns the part of threshold which isn't elapsed yet, within [0, threshold].
+// Elapsed time may be negative if the creation time is reported by a peer with a skewed clock.
+func remaining(threshold, elapsed time.Duration) time.Duration {
+	if elapsed < 0 {
+		return threshold
+	}
+	if elapsed > threshold {
+		return 0
+	}
+	return threshold - elapsed
+}
+
+// conflicts returns true if the events cannot belong to the same self-parents chain
+func conflicts(a, b *JournalRecord) bool {
+	if a.Epoch != b.Epoch || a.ID == b.ID {
+		return false
+	}
+	return a.Seq == b.Seq || (a.Seq < b.Seq) != (a.Lamport < b.Lamport)
+}
+
+// InspectParallelInstance should be called after downloading self-events which weren't created on this instance.
+// Unlike DetectParallelInstance, it also takes into account the seq and Lamport time of the events relative to
+// the last emitted self-event (nil if unknown), and the creation times reported by peers.
+// The verdict contains the confidence level, the events which triggered it, and the recommended wait.
+// The confidence is derived only from the recorded evidence, so SyncStatus.ExternalSelfEventCreated isn't used,
+// the event which set it should be passed among the external events instead.
+func InspectParallelInstance(s SyncStatus, last *JournalRecord, external []ExternalSelfEvent, threshold time.Duration) ParallelInstanceVerdict {
+	var v ParallelInstanceVerdict
+	seen := make([]*JournalRecord, 0, len(external))
+	for _, ext := range external {
+		e := &JournalRecord{
+			Epoch:   ext.Event.Epoch(),
+			Seq:     ext.Event.Seq(),
+			Lamport: ext.Event.Lamport(),
+			ID:      ext.Event.ID(),
+		}
+		if last != nil && last.ID == e.ID {
+			continue
+		}
+		conflicting := last != nil && conflicts(last, e)
+		for _, prev := range seen {
+			conflicting = conflicting || conflicts(prev, e)
+		}
+		seen = append(seen, e)
+
+		switch {
+		case conflicting:
+			v.add(e.ID, ErrConflictingSelfEvent, CertainParallelInstance, threshold)
+		case last != nil && (e.Epoch > last.Epoch || e.Epoch == last.Epoch && e.Seq > last.Seq):
+			v.add(e.ID, ErrSelfEventAhead, LikelyParallelInstance, threshold)
+		case !ext.Created.Before(s.Startup):
+			v.add(e.ID, ErrSelfEventCreatedAfterStart, LikelyParallelInstance, remaining(threshold, s.Since(ext.Created)))
+		case s.Since(ext.Created) < threshold:
+			v.add(e.ID, ErrRecentSelfEvent, PossibleParallelInstance, remaining(threshold, s.Since(ext.Created)))
+		}
+	}
+	return v
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 )
 
 func TestDetectParallelInstance(t *testing.T) {
@@ -39,3 +41,109 @@ func TestDetectParallelInstance(t *testing.T) {
 		require.False(t, DetectParallelInstance(s, 2*time.Hour))
 	}
 }
+
+func TestInspectParallelInstance(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(10000, 0)
+	threshold := time.Hour
+	s := SyncStatus{
+		Now:     now,
+		Startup: now.Add(-10 * time.Minute),
+	}
+	e1 := fakeSelfEvent(2, 1, 1, nil)
+	e1ID := e1.ID()
+	e2 := fakeSelfEvent(2, 2, 5, &e1ID)
+	last := &JournalRecord{
+		Epoch:   e2.Epoch(),
+		Seq:     e2.Seq(),
+		Lamport: e2.Lamport(),
+		ID:      e2.ID(),
+	}
+	ext := func(epoch idx.Epoch, seq idx.Event, lamport idx.Lamport, created time.Time) ExternalSelfEvent {
+		return ExternalSelfEvent{
+			Event:   fakeSelfEvent(epoch, seq, lamport, nil),
+			Created: created,
+		}
+	}
+
+	// no external events
+	v := InspectParallelInstance(s, last, nil, threshold)
+	require.Equal(NoParallelInstance, v.Confidence)
+	require.Empty(v.Evidence)
+	require.Zero(v.Wait)
+	require.False(v.Halt())
+
+	// own events, created before the startup long ago
+	v = InspectParallelInstance(s, last, []ExternalSelfEvent{
+		{e1, now.Add(-2 * time.Hour)},
+		{e2, now.Add(-2 * time.Hour)},
+	}, threshold)
+	require.Equal(NoParallelInstance, v.Confidence)
+
+	// created recently, before the startup
+	recent := ext(2, 1, 1, now.Add(-20*time.Minute))
+	v = InspectParallelInstance(s, nil, []ExternalSelfEvent{recent}, threshold)
+	require.Equal(PossibleParallelInstance, v.Confidence)
+	require.Equal([]Evidence{{recent.Event.ID(), ErrRecentSelfEvent}}, v.Evidence)
+	require.Equal(40*time.Minute, v.Wait)
+
+	// created after the startup
+	afterStart := ext(1, 1, 1, now.Add(-5*time.Minute))
+	v = InspectParallelInstance(s, last, []ExternalSelfEvent{afterStart}, threshold)
+	require.Equal(LikelyParallelInstance, v.Confidence)
+	require.Equal([]Evidence{{afterStart.Event.ID(), ErrSelfEventCreatedAfterStart}}, v.Evidence)
+	require.Equal(55*time.Minute, v.Wait)
+
+	// seq is ahead of the last emitted event, regardless of the reported creation time
+	ahead := ext(2, 4, 10, now.Add(-10*time.Hour))
+	nextEpoch := ext(3, 1, 1, now.Add(-10*time.Hour))
+	v = InspectParallelInstance(s, last, []ExternalSelfEvent{ahead, nextEpoch}, threshold)
+	require.Equal(LikelyParallelInstance, v.Confidence)
+	require.Equal([]Evidence{{ahead.Event.ID(), ErrSelfEventAhead}, {nextEpoch.Event.ID(), ErrSelfEventAhead}}, v.Evidence)
+	require.Equal(threshold, v.Wait)
+	require.False(v.Halt())
+
+	// conflicts with the last emitted event
+	for _, conflicting := range []ExternalSelfEvent{
+		ext(2, 2, 6, now.Add(-10*time.Hour)),
+		ext(2, 1, 5, now.Add(-10*time.Hour)),
+		ext(2, 3, 5, now.Add(-10*time.Hour)),
+	} {
+		v = InspectParallelInstance(s, last, []ExternalSelfEvent{recent, conflicting}, threshold)
+		require.Equal(CertainParallelInstance, v.Confidence)
+		require.Equal(Evidence{conflicting.Event.ID(), ErrConflictingSelfEvent}, v.Evidence[1])
+		require.True(v.Halt())
+	}
+
+	// conflicting external events, without the last emitted event
+	v = InspectParallelInstance(s, nil, []ExternalSelfEvent{
+		ext(2, 3, 6, now.Add(-10*time.Hour)),
+		ext(2, 3, 7, now.Add(-10*time.Hour)),
+	}, threshold)
+	require.Equal(CertainParallelInstance, v.Confidence)
+	require.Len(v.Evidence, 1)
+
+	// created after the startup, but longer than threshold ago
+	v = InspectParallelInstance(s, last, []ExternalSelfEvent{afterStart}, time.Minute)
+	require.Equal(LikelyParallelInstance, v.Confidence)
+	require.Equal([]Evidence{{afterStart.Event.ID(), ErrSelfEventCreatedAfterStart}}, v.Evidence)
+	require.Zero(v.Wait)
+
+	// creation time is in the future
+	future := ext(1, 1, 1, now.Add(time.Hour))
+	v = InspectParallelInstance(s, last, []ExternalSelfEvent{future}, threshold)
+	require.Equal(threshold, v.Wait)
+
+	// no confidence without evidence, even if the sync status reports a recent external self-event
+	s.ExternalSelfEventCreated = now.Add(-time.Minute)
+	v = InspectParallelInstance(s, last, nil, threshold)
+	require.Equal(NoParallelInstance, v.Confidence)
+	require.Empty(v.Evidence)
+	require.Zero(v.Wait)
+	for _, ext := range []ExternalSelfEvent{recent, afterStart, ahead} {
+		v = InspectParallelInstance(s, last, []ExternalSelfEvent{ext}, threshold)
+		require.NotEqual(NoParallelInstance, v.Confidence)
+		require.NotEmpty(v.Evidence)
+	}
+}
@@ -0,0 +1,328 @@
+package emitter
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/emitter/ancestor"
+	"github.com/Fantom-foundation/lachesis-base/emitter/doublesign"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+var (
+	ErrNotValidator      = errors.New("not a validator in the current epoch")
+	ErrForeignSelfParent = errors.New("self-parent isn't an event of the validator in the current epoch")
+)
+
+// Callbacks connects Emitter to the application.
+// Callbacks are called under the emitter lock, and must not call Emitter methods.
+type Callbacks struct {
+	// GetEpochValidators returns the current epoch and its validators
+	GetEpochValidators func() (*pos.Validators, idx.Epoch)
+	// GetEvent returns a known event
+	GetEvent func(hash.Event) dag.Event
+	// GetHeads returns the events of the epoch which have no descendants
+	GetHeads func(idx.Epoch) hash.Events
+	// GetLastEvent returns the last known event of the validator in the epoch
+	GetLastEvent func(idx.Epoch, idx.ValidatorID) *hash.Event
+	// GetSyncStatus returns the node status, which is used to protect against doublesigning
+	GetSyncStatus func() doublesign.SyncStatus
+
+	// NewEvent creates an empty event of the application type
+	NewEvent func() dag.MutableEvent
+	// Payload fills the application payload of the event, after the consensus fields are built.
+	// It's called only if the event is going to be emitted, or if PayloadBacklog isn't set and the event is emitted only with a payload.
+	// Returns true if the event carries a payload, which should be emitted without a delay.
+	// The event may be discarded afterwards, so the payload must not be dropped from its source until the event is connected
+	Payload func(e dag.MutableEvent) bool
+	// PayloadBacklog returns the number of payload items waiting to be emitted.
+	// If it's not set, an event with a payload is emitted every MinEmitInterval
+	PayloadBacklog func() int
+	// Build fills the consensus fields of the event, e.g. lachesis.Consensus.Build
+	Build func(e dag.MutableEvent) error
+	// Sign sets the final event ID and signs the event
+	Sign func(e dag.MutableEvent) error
+	// Process connects the emitted event, e.g. saves it and calls lachesis.Consensus.Process
+	Process func(e dag.Event) error
+	// Broadcast sends the connected event to peers
+	Broadcast func(e dag.Event)
+}
+
+// Emitter decides when to emit self-events, chooses the parents and builds the events.
+type Emitter struct {
+	cfg Config
+
+	callback Callbacks
+	dagIndex ancestor.DagIndex
+	journal  *doublesign.Journal
+	gate     Gate
+
+	mu sync.Mutex
+
+	epoch          idx.Epoch
+	validators     *pos.Validators
+	quorumIndexer  *ancestor.QuorumIndexer
+	intervals      *IntervalController
+	prevEmittedAt  time.Time
+	emittedInEpoch bool
+
+	quit     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// New creates Emitter instance.
+// dagIndex must index all the connected events of the current epoch.
+func New(cfg Config, dagIndex ancestor.DagIndex, callback Callbacks) *Emitter {
+	return &Emitter{
+		cfg:      cfg,
+		callback: callback,
+		dagIndex: dagIndex,
+		quit:     make(chan struct{}),
+	}
+}
+
+// SetJournal sets a durable journal of emitted self-events.
+// Events which conflict with the journal aren't emitted.
+func (em *Emitter) SetJournal(journal *doublesign.Journal) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.journal = journal
+}
+
+// SetGate sets a gate which is checked before emitting, e.g. NewHealthGate.
+// Events aren't emitted while the gate is closed, and EmitEvent returns the error of the gate decision.
+func (em *Emitter) SetGate(gate Gate) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.gate = gate
+}
+
+// Start runs the emission loop, which tries to emit an event every MinEmitInterval.
+// Returns an error if the config isn't valid for the loop.
+func (em *Emitter) Start() error {
+	if err := em.cfg.Validate(); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(em.cfg.MinEmitInterval)
+	em.loop(ticker.C, ticker.Stop)
+	return nil
+}
+
+// loop tries to emit an event on each tick, until the emitter is stopped
+func (em *Emitter) loop(tick <-chan time.Time, stopTicker func()) {
+	em.wg.Add(1)
+	go func() {
+		defer em.wg.Done()
+		defer stopTicker()
+		for {
+			select {
+			case <-tick:
+				// errors mean that the event isn't emitted now, it'll be retried on a next tick
+				_, _ = em.EmitEvent()
+			case <-em.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop interrupts the emission loop and waits until it's finished.
+// It's safe to call Stop several times.
+func (em *Emitter) Stop() {
+	em.stopOnce.Do(func() {
+		close(em.quit)
+	})
+	em.wg.Wait()
+}
+
+// OnEventConnected should be called for each connected event which wasn't emitted by this Emitter.
+func (em *Emitter) OnEventConnected(e dag.Event) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	em.syncEpoch()
+	em.processEvent(e)
+}
+
+func (em *Emitter) processEvent(e dag.Event) {
+	if e.Epoch() != em.epoch {
+		return
+	}
+	em.quorumIndexer.ProcessEvent(e, e.Creator() == em.cfg.Validator)
+}
+
+// syncEpoch resets the epoch state if epoch is changed
+func (em *Emitter) syncEpoch() {
+	validators, epoch := em.callback.GetEpochValidators()
+	if em.quorumIndexer != nil && epoch == em.epoch {
+		return
+	}
+	em.epoch = epoch
+	em.validators = validators
+	em.quorumIndexer = ancestor.NewQuorumIndexer(validators, em.dagIndex, newDiffMetricFn(validators))
+	em.intervals = NewIntervalController(em.cfg, validators)
+	em.emittedInEpoch = false
+}
+
+// EmitEvent emits a new self-event if it's the time to emit.
+// Returns nil event if there's no reason to emit now.
+// Returns an error if emitting is prohibited or if the event cannot be built.
+func (em *Emitter) EmitEvent() (dag.Event, error) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	em.syncEpoch()
+	if !em.validators.Exists(em.cfg.Validator) {
+		return nil, ErrNotValidator
+	}
+
+	status := em.callback.GetSyncStatus()
+	if _, err := doublesign.SyncedToEmit(status, em.cfg.DoublesignProtection); err != nil {
+		return nil, err
+	}
+	if em.gate != nil {
+		if d := em.gate.Check(); !d.Open() {
+			return nil, d.Err
+		}
+	}
+	sinceLast := status.Since(em.prevEmittedAt)
+	if em.emittedInEpoch && sinceLast < em.cfg.MinEmitInterval {
+		return nil, nil
+	}
+
+	selfParent := em.callback.GetLastEvent(em.epoch, em.cfg.Validator)
+	seq := idx.Event(1)
+	if selfParent != nil {
+		// never continue a self-parent chain of another validator, e.g. of another local validator ID
+		sp := em.callback.GetEvent(*selfParent)
+		if sp == nil || sp.Creator() != em.cfg.Validator || sp.Epoch() != em.epoch {
+			return nil, ErrForeignSelfParent
+		}
+		seq = sp.Seq() + 1
+	}
+	parents := em.chooseParents(selfParent)
+
+	e := em.callback.NewEvent()
+	e.SetEpoch(em.epoch)
+	e.SetCreator(em.cfg.Validator)
+	e.SetParents(parents)
+	e.SetSeq(seq)
+	var maxLamport idx.Lamport
+	for _, p := range parents {
+		parent := em.callback.GetEvent(p)
+		if maxLamport < parent.Lamport() {
+			maxLamport = parent.Lamport()
+		}
+	}
+	e.SetLamport(maxLamport + 1)
+
+	// consensus fields are needed to estimate the frame progress
+	if err := em.callback.Build(e); err != nil {
+		return nil, err
+	}
+	intervalPassed := !em.emittedInEpoch || sinceLast >= em.intervals.Interval(em.emissionState(e))
+	if !intervalPassed && em.callback.PayloadBacklog != nil {
+		return nil, nil
+	}
+	// the payload is filled only if the event is emitted, or if only a payload allows to emit it,
+	// because an event with a payload is emitted every MinEmitInterval if PayloadBacklog isn't set
+	hasPayload := false
+	if em.callback.Payload != nil {
+		hasPayload = em.callback.Payload(e)
+	}
+	if !intervalPassed && !hasPayload {
+		return nil, nil
+	}
+
+	if em.callback.Sign != nil {
+		if err := em.callback.Sign(e); err != nil {
+			return nil, err
+		}
+	}
+	if em.journal != nil {
+		if err := em.journal.Check(e); err != nil {
+			return nil, err
+		}
+		// the event must be recorded on disk before it's connected, so that it cannot be re-emitted differently
+		// if the node crashes or if Process fails after the event is partially saved
+		if err := em.journal.Record(e); err != nil {
+			return nil, err
+		}
+	}
+	if err := em.callback.Process(e); err != nil {
+		return nil, err
+	}
+	em.processEvent(e)
+	em.prevEmittedAt = status.Now
+	em.emittedInEpoch = true
+
+	if em.callback.Broadcast != nil {
+		em.callback.Broadcast(e)
+	}
+	return e, nil
+}
+
+// chooseParents returns the self-parent (if any) and the best heads, according to QuorumIndexer
+func (em *Emitter) chooseParents(selfParent *hash.Event) hash.Events {
+	var existingParents hash.Events
+	if selfParent != nil {
+		existingParents = append(existingParents, *selfParent)
+	}
+	strategies := make([]ancestor.SearchStrategy, 0, em.cfg.MaxParents)
+	for len(strategies)+len(existingParents) < em.cfg.MaxParents {
+		strategies = append(strategies, em.quorumIndexer.SearchStrategy())
+	}
+	return ancestor.ChooseParents(existingParents, em.callback.GetHeads(em.epoch), strategies)
+}
+
+// emissionState returns the state of the candidate event, which is used to calculate the emission interval
+func (em *Emitter) emissionState(e dag.Event) EmissionState {
+	s := EmissionState{
+		MedianSeqs:     em.quorumIndexer.GetGlobalMedianSeqs(),
+		SelfParentSeqs: em.quorumIndexer.GetSelfParentSeqs(),
+		ParentsSeqs:    make([]idx.Event, em.validators.Len()),
+	}
+	if e.SelfParent() != nil {
+		s.FrameAdvance = e.Frame() > em.callback.GetEvent(*e.SelfParent()).Frame()
+	}
+	for _, p := range e.Parents() {
+		vecClock := em.dagIndex.GetMergedHighestBefore(p)
+		for i := range s.ParentsSeqs {
+			seq := vecClock.Get(idx.Validator(i))
+			if !seq.IsForkDetected() && s.ParentsSeqs[i] < seq.Seq() {
+				s.ParentsSeqs[i] = seq.Seq()
+			}
+		}
+	}
+	if em.callback.PayloadBacklog != nil {
+		s.PayloadBacklog = em.callback.PayloadBacklog()
+	}
+	return s
+}
+
+// newDiffMetricFn returns a metric of a parent, which is the stake of validators which events are
+// observed by the parent and aren't yet observed by the quorum, capped to a few events per validator
+func newDiffMetricFn(validators *pos.Validators) ancestor.DiffMetricFn {
+	const maxDiff = 2
+	capFn := func(diff idx.Event, weight pos.Weight) ancestor.Metric {
+		if diff > maxDiff {
+			return ancestor.Metric(maxDiff * weight)
+		}
+		return ancestor.Metric(diff) * ancestor.Metric(weight)
+	}
+	return func(median, current, update idx.Event, validatorIdx idx.Validator) ancestor.Metric {
+		if update <= median || update <= current {
+			return 0
+		}
+		weight := validators.GetWeightByIdx(validatorIdx)
+		if median < current {
+			return capFn(update-median, weight) - capFn(current-median, weight)
+		}
+		return capFn(update-median, weight)
+	}
+}
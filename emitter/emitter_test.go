@@ -0,0 +1,427 @@
+package emitter
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/abft"
+	"github.com/Fantom-foundation/lachesis-base/emitter/doublesign"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/Fantom-foundation/lachesis-base/lachesis"
+	"github.com/Fantom-foundation/lachesis-base/utils/adapters"
+	"github.com/Fantom-foundation/lachesis-base/vecfc"
+)
+
+// testWorld is a node which is shared by all the emitters
+type testWorld struct {
+	validators *pos.Validators
+	events     map[hash.Event]dag.Event
+	heads      hash.EventsSet
+	lasts      map[idx.ValidatorID]hash.Event
+	lch        *abft.IndexedLachesis
+	dagIndex   *vecfc.Index
+	blocks     int
+	now        time.Time
+	status     doublesign.SyncStatus
+
+	emitters  []*Emitter
+	broadcast dag.Events
+}
+
+func newTestWorld(t *testing.T, validators *pos.Validators) *testWorld {
+	w := &testWorld{
+		validators: validators,
+		events:     map[hash.Event]dag.Event{},
+		heads:      hash.EventsSet{},
+		lasts:      map[idx.ValidatorID]hash.Event{},
+		now:        time.Unix(1000, 0),
+	}
+	crit := func(err error) {
+		t.Fatal(err)
+	}
+	store := abft.NewMemStore()
+	require.NoError(t, store.ApplyGenesis(&abft.Genesis{
+		Validators: validators,
+		Epoch:      abft.FirstEpoch,
+	}))
+	w.dagIndex = vecfc.NewIndex(crit, vecfc.LiteConfig())
+	w.lch = abft.NewIndexedLachesis(store, w, &adapters.VectorToDagIndexer{Index: w.dagIndex}, crit, abft.LiteConfig())
+	require.NoError(t, w.lch.Bootstrap(lachesis.ConsensusCallbacks{
+		BeginBlock: func(block *lachesis.Block) lachesis.BlockCallbacks {
+			w.blocks++
+			return lachesis.BlockCallbacks{}
+		},
+	}))
+	w.status = doublesign.SyncStatus{
+		PeersNum:  1,
+		P2PSynced: w.now,
+	}
+	return w
+}
+
+func (w *testWorld) GetEvent(id hash.Event) dag.Event {
+	return w.events[id]
+}
+
+func (w *testWorld) HasEvent(id hash.Event) bool {
+	_, ok := w.events[id]
+	return ok
+}
+
+func (w *testWorld) process(e dag.Event) error {
+	w.events[e.ID()] = e
+	if err := w.lch.Process(e); err != nil {
+		delete(w.events, e.ID())
+		return err
+	}
+	for _, p := range e.Parents() {
+		w.heads.Erase(p)
+	}
+	w.heads.Add(e.ID())
+	w.lasts[e.Creator()] = e.ID()
+	return nil
+}
+
+func (w *testWorld) callbacks(payload func(e dag.MutableEvent) bool) Callbacks {
+	return Callbacks{
+		GetEpochValidators: func() (*pos.Validators, idx.Epoch) {
+			return w.validators, abft.FirstEpoch
+		},
+		GetEvent: w.GetEvent,
+		GetHeads: func(idx.Epoch) hash.Events {
+			return w.heads.Slice()
+		},
+		GetLastEvent: func(_ idx.Epoch, v idx.ValidatorID) *hash.Event {
+			last, ok := w.lasts[v]
+			if !ok {
+				return nil
+			}
+			return &last
+		},
+		GetSyncStatus: func() doublesign.SyncStatus {
+			s := w.status
+			s.Now = w.now
+			return s
+		},
+		NewEvent: func() dag.MutableEvent {
+			return &tdag.TestEvent{}
+		},
+		Payload: payload,
+		Build:   w.lch.Build,
+		Sign: func(e dag.MutableEvent) error {
+			hasher := sha256.New()
+			hasher.Write(e.(*tdag.TestEvent).Bytes())
+			var id [24]byte
+			copy(id[:], hasher.Sum(nil)[:24])
+			e.SetID(id)
+			return nil
+		},
+		Process: w.process,
+		Broadcast: func(e dag.Event) {
+			w.broadcast = append(w.broadcast, e)
+		},
+	}
+}
+
+func (w *testWorld) newEmitter(validator idx.ValidatorID, payload func(e dag.MutableEvent) bool) *Emitter {
+	em := New(LiteConfig(validator), &adapters.VectorToDagIndexer{Index: w.dagIndex}, w.callbacks(payload))
+	w.emitters = append(w.emitters, em)
+	return em
+}
+
+// connect notifies other emitters about the emitted event
+func (w *testWorld) connect(e dag.Event, emitter *Emitter) {
+	for _, em := range w.emitters {
+		if em != emitter {
+			em.OnEventConnected(e)
+		}
+	}
+}
+
+func TestEmitter(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(5)
+	validators := pos.ArrayToValidators(nodes, []pos.Weight{1, 2, 3, 4, 5})
+	w := newTestWorld(t, validators)
+	for _, v := range nodes {
+		w.newEmitter(v, nil)
+	}
+
+	r := rand.New(rand.NewSource(0))
+	emitted := 0
+	for round := 0; round < 100; round++ {
+		w.now = w.now.Add(time.Millisecond)
+		for _, i := range r.Perm(len(w.emitters)) {
+			em := w.emitters[i]
+			e, err := em.EmitEvent()
+			require.NoError(err)
+			if e == nil {
+				continue
+			}
+			emitted++
+			w.connect(e, em)
+
+			require.Equal(abft.FirstEpoch, e.Epoch())
+			require.Equal(nodes[i], e.Creator())
+			require.LessOrEqual(len(e.Parents()), em.cfg.MaxParents)
+			if e.SelfParent() != nil {
+				selfParent := w.GetEvent(*e.SelfParent())
+				require.Equal(e.Parents()[0], selfParent.ID())
+				require.Equal(selfParent.Seq()+1, e.Seq())
+			} else {
+				require.Equal(idx.Event(1), e.Seq())
+			}
+			for _, p := range e.Parents() {
+				require.Less(w.GetEvent(p).Lamport(), e.Lamport())
+			}
+			require.NotZero(e.Frame())
+		}
+	}
+	require.Equal(emitted, len(w.broadcast))
+	require.Greater(emitted, len(nodes)*10)
+	require.Greater(w.blocks, 10)
+}
+
+func TestEmitter_WhenToEmit(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(3)
+	validators := pos.EqualWeightValidators(nodes, 1)
+	w := newTestWorld(t, validators)
+	payloads := 0
+	em := w.newEmitter(nodes[0], func(e dag.MutableEvent) bool {
+		return payloads > 0
+	})
+	other := w.newEmitter(nodes[1], nil)
+
+	// doublesign protection
+	w.status.PeersNum = 0
+	e, err := em.EmitEvent()
+	require.Nil(e)
+	require.Equal(doublesign.ErrNoConnections, err)
+	w.status.PeersNum = 1
+
+	// not a validator
+	e, err = w.newEmitter(idx.ValidatorID(100), nil).EmitEvent()
+	require.Nil(e)
+	require.Equal(ErrNotValidator, err)
+
+	// first event is emitted without a delay
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+
+	// nothing to emit, no progress and no payload
+	w.now = w.now.Add(em.cfg.MinEmitInterval)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.Nil(e)
+
+	// payload is emitted after MinEmitInterval
+	payloads = 1
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.Nil(e)
+	payloads = 0
+
+	// new events of other validators are a progress, which shortens the interval
+	w.now = w.now.Add(em.cfg.MinEmitInterval)
+	oe, err := other.EmitEvent()
+	require.NoError(err)
+	require.NotNil(oe)
+	w.connect(oe, other)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.Nil(e)
+	w.now = w.now.Add(em.cfg.MaxEmitInterval / 2)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+	require.Contains(e.Parents(), oe.ID())
+
+	// event is emitted after MaxEmitInterval even if there's nothing to emit
+	w.now = w.now.Add(em.cfg.MaxEmitInterval - time.Nanosecond)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.Nil(e)
+	w.now = w.now.Add(time.Nanosecond)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+}
+
+func TestEmitter_Loop(t *testing.T) {
+	nodes := tdag.GenNodes(1)
+	w := newTestWorld(t, pos.EqualWeightValidators(nodes, 1))
+	em := w.newEmitter(nodes[0], nil)
+	// the world isn't thread-safe, so only the loop uses it, and the time advances on each tick
+	getSyncStatus := em.callback.GetSyncStatus
+	em.callback.GetSyncStatus = func() doublesign.SyncStatus {
+		w.now = w.now.Add(em.cfg.MinEmitInterval / 2)
+		return getSyncStatus()
+	}
+	tick := make(chan time.Time)
+	stopped := false
+	em.loop(tick, func() {
+		stopped = true
+	})
+	// every event of the single validator advances the frame, so an event is emitted on every second tick, after MinEmitInterval
+	for i := 0; i < 6; i++ {
+		tick <- time.Time{}
+	}
+	em.Stop()
+	em.Stop()
+	require.True(t, stopped)
+	require.Len(t, w.broadcast, 3)
+}
+
+func TestEmitter_StartStop(t *testing.T) {
+	nodes := tdag.GenNodes(1)
+	w := newTestWorld(t, pos.EqualWeightValidators(nodes, 1))
+	em := w.newEmitter(nodes[0], nil)
+	require.NoError(t, em.Start())
+	em.Stop()
+	em.Stop()
+}
+
+func TestEmitter_PayloadAfterInterval(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(3)
+	validators := pos.EqualWeightValidators(nodes, 1)
+	w := newTestWorld(t, validators)
+	payloads, filled := 0, 0
+	em := w.newEmitter(nodes[0], func(e dag.MutableEvent) bool {
+		filled++
+		return payloads > 0
+	})
+	e, err := em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+	require.Equal(1, filled)
+
+	// without PayloadBacklog, the payload is filled to find out whether the event may be emitted
+	w.now = w.now.Add(em.cfg.MinEmitInterval)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.Nil(e)
+	require.Equal(2, filled)
+	payloads = 1
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+	require.Equal(3, filled)
+
+	// with PayloadBacklog, the payload isn't filled until the interval passes
+	em.callback.PayloadBacklog = func() int {
+		return 0
+	}
+	w.now = w.now.Add(em.cfg.MinEmitInterval)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.Nil(e)
+	require.Equal(3, filled)
+	w.now = w.now.Add(em.cfg.MaxEmitInterval)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+	require.Equal(4, filled)
+}
+
+func TestEmitter_InvalidConfig(t *testing.T) {
+	nodes := tdag.GenNodes(1)
+	w := newTestWorld(t, pos.EqualWeightValidators(nodes, 1))
+	em := w.newEmitter(nodes[0], nil)
+	em.cfg.MinEmitInterval = 0
+	require.Equal(t, ErrInvalidEmitInterval, em.Start())
+	em.Stop()
+}
+
+func TestEmitter_Journal(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(1)
+	validators := pos.EqualWeightValidators(nodes, 1)
+	journalDB := memorydb.New()
+
+	w := newTestWorld(t, validators)
+	em := w.newEmitter(nodes[0], nil)
+	em.SetJournal(doublesign.NewJournal(journalDB))
+	for i := 0; i < 3; i++ {
+		w.now = w.now.Add(em.cfg.MaxEmitInterval)
+		e, err := em.EmitEvent()
+		require.NoError(err)
+		require.NotNil(e)
+	}
+	last := w.broadcast[len(w.broadcast)-1]
+	r, err := doublesign.NewJournal(journalDB).Get(nodes[0])
+	require.NoError(err)
+	require.Equal(last.ID(), r.ID)
+
+	// the node DB is restored from an older backup, without the emitted events
+	restored := newTestWorld(t, validators)
+	em = restored.newEmitter(nodes[0], nil)
+	em.SetJournal(doublesign.NewJournal(journalDB))
+	e, err := em.EmitEvent()
+	require.Nil(e)
+	require.Equal(doublesign.ErrConflictingEmission, err)
+	require.Empty(restored.broadcast)
+}
+
+// failingJournalDB is a journal DB which fails to write to disk
+type failingJournalDB struct {
+	*memorydb.Database
+	err error
+}
+
+func (db failingJournalDB) SyncPut(key []byte, value []byte) error {
+	return db.err
+}
+
+func TestEmitter_JournalRecordedBeforeProcess(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(1)
+	validators := pos.EqualWeightValidators(nodes, 1)
+	w := newTestWorld(t, validators)
+	em := w.newEmitter(nodes[0], nil)
+
+	// the event isn't connected nor broadcast, if it cannot be recorded
+	errDisk := errors.New("disk failure")
+	em.SetJournal(doublesign.NewJournal(failingJournalDB{memorydb.New(), errDisk}))
+	e, err := em.EmitEvent()
+	require.Nil(e)
+	require.Equal(errDisk, err)
+	require.Empty(w.events)
+	require.Empty(w.broadcast)
+
+	// the event is recorded, even if it fails to get connected
+	journalDB := memorydb.New()
+	em.SetJournal(doublesign.NewJournal(journalDB))
+	errProcess := errors.New("process failure")
+	em.callback.Process = func(e dag.Event) error {
+		return errProcess
+	}
+	e, err = em.EmitEvent()
+	require.Nil(e)
+	require.Equal(errProcess, err)
+	require.Empty(w.broadcast)
+	r, err := doublesign.NewJournal(journalDB).Get(nodes[0])
+	require.NoError(err)
+	require.NotNil(r)
+	require.Equal(idx.Event(1), r.Seq)
+}
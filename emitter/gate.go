@@ -0,0 +1,173 @@
+package emitter
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/emitter/doublesign"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+var (
+	ErrEventsOverloaded = errors.New("events processing is overloaded")
+	ErrEventsBuffered   = errors.New("too many events are buffered")
+	ErrFetcherBacklog   = errors.New("too many events are waiting to be fetched")
+	ErrFrameLag         = errors.New("decided frame lags behind peers")
+)
+
+// GateReason is a code of the reason why the emission is prohibited
+type GateReason uint8
+
+const (
+	GateOpen GateReason = iota
+	GateNotSynced
+	GateOverloaded
+	GateBuffered
+	GateFetcherBacklog
+	GateFrameLag
+)
+
+func (r GateReason) String() string {
+	switch r {
+	case GateOpen:
+		return "open"
+	case GateNotSynced:
+		return "not synced"
+	case GateOverloaded:
+		return "overloaded"
+	case GateBuffered:
+		return "buffered"
+	case GateFetcherBacklog:
+		return "fetcher backlog"
+	case GateFrameLag:
+		return "frame lag"
+	}
+	return "unknown"
+}
+
+// GateDecision is a result of Gate check
+type GateDecision struct {
+	Reason GateReason
+	// Err describes the reason, it's nil if the gate is open
+	Err error
+	// Wait is a minimum duration to wait before emitting, if it's known
+	Wait time.Duration
+}
+
+// Open returns true if emitting is allowed
+func (d GateDecision) Open() bool {
+	return d.Reason == GateOpen
+}
+
+// Gate decides whether the node is healthy enough to emit events
+type Gate interface {
+	Check() GateDecision
+}
+
+// GateFn is a function which implements Gate
+type GateFn func() GateDecision
+
+// Check calls the function
+func (fn GateFn) Check() GateDecision {
+	return fn()
+}
+
+// AllGates returns a gate which is open only if all the gates are open.
+// The gates are checked in the specified order, and the first closed gate decides.
+func AllGates(gates ...Gate) Gate {
+	return GateFn(func() GateDecision {
+		for _, g := range gates {
+			if d := g.Check(); !d.Open() {
+				return d
+			}
+		}
+		return GateDecision{}
+	})
+}
+
+// SyncedGate is closed while doublesign.SyncedToEmit prohibits emitting
+func SyncedGate(getStatus func() doublesign.SyncStatus, threshold time.Duration) Gate {
+	return GateFn(func() GateDecision {
+		wait, err := doublesign.SyncedToEmit(getStatus(), threshold)
+		if err != nil {
+			return GateDecision{GateNotSynced, err, wait}
+		}
+		return GateDecision{}
+	})
+}
+
+// OverloadedGate is closed while the component is overloaded, e.g. dagprocessor.Processor.Overloaded
+func OverloadedGate(overloaded func() bool) Gate {
+	return GateFn(func() GateDecision {
+		if overloaded() {
+			return GateDecision{Reason: GateOverloaded, Err: ErrEventsOverloaded}
+		}
+		return GateDecision{}
+	})
+}
+
+// BufferedGate is closed while the number or the size of buffered events exceeds the limit,
+// e.g. dagprocessor.Processor.TotalBuffered
+func BufferedGate(totalBuffered func() dag.Metric, limit dag.Metric) Gate {
+	return GateFn(func() GateDecision {
+		total := totalBuffered()
+		if total.Num > limit.Num || total.Size > limit.Size {
+			return GateDecision{Reason: GateBuffered, Err: ErrEventsBuffered}
+		}
+		return GateDecision{}
+	})
+}
+
+// FetcherBacklogGate is closed while the number of events which are waiting to be fetched exceeds the limit,
+// e.g. itemsfetcher.Fetcher.Backlog
+func FetcherBacklogGate(backlog func() int, limit int) Gate {
+	return GateFn(func() GateDecision {
+		if backlog() > limit {
+			return GateDecision{Reason: GateFetcherBacklog, Err: ErrFetcherBacklog}
+		}
+		return GateDecision{}
+	})
+}
+
+// FrameLagGate is closed while the last decided frame lags behind the highest decided frame reported by peers
+// by more than maxLag frames
+func FrameLagGate(decidedFrames func() (local, peers idx.Frame), maxLag idx.Frame) Gate {
+	return GateFn(func() GateDecision {
+		local, peers := decidedFrames()
+		if peers > local+maxLag {
+			return GateDecision{Reason: GateFrameLag, Err: ErrFrameLag}
+		}
+		return GateDecision{}
+	})
+}
+
+// GateSignals are the health signals of the node. Nil signals aren't checked
+type GateSignals struct {
+	// Overloaded returns true if events processing is overloaded, e.g. dagprocessor.Processor.Overloaded
+	Overloaded func() bool
+	// TotalBuffered returns the buffered events, e.g. dagprocessor.Processor.TotalBuffered
+	TotalBuffered func() dag.Metric
+	// FetcherBacklog returns the number of events waiting to be fetched, e.g. itemsfetcher.Fetcher.Backlog
+	FetcherBacklog func() int
+	// DecidedFrames returns the last decided frame of the node, and the highest decided frame reported by peers
+	DecidedFrames func() (local, peers idx.Frame)
+}
+
+// NewHealthGate returns a gate which checks all the non-nil signals, from the cheapest ones
+func NewHealthGate(cfg GateConfig, signals GateSignals) Gate {
+	var gates []Gate
+	if signals.Overloaded != nil {
+		gates = append(gates, OverloadedGate(signals.Overloaded))
+	}
+	if signals.TotalBuffered != nil {
+		gates = append(gates, BufferedGate(signals.TotalBuffered, cfg.MaxBuffered))
+	}
+	if signals.FetcherBacklog != nil {
+		gates = append(gates, FetcherBacklogGate(signals.FetcherBacklog, cfg.MaxFetcherBacklog))
+	}
+	if signals.DecidedFrames != nil {
+		gates = append(gates, FrameLagGate(signals.DecidedFrames, cfg.MaxFrameLag))
+	}
+	return AllGates(gates...)
+}
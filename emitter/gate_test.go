@@ -0,0 +1,113 @@
+package emitter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/emitter/doublesign"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+func TestHealthGate(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		overloaded     bool
+		buffered       dag.Metric
+		backlog        int
+		local, peers   idx.Frame
+		overloadedCall int
+	)
+	cfg := DefaultGateConfig()
+	gate := NewHealthGate(cfg, GateSignals{
+		Overloaded: func() bool {
+			overloadedCall++
+			return overloaded
+		},
+		TotalBuffered: func() dag.Metric {
+			return buffered
+		},
+		FetcherBacklog: func() int {
+			return backlog
+		},
+		DecidedFrames: func() (idx.Frame, idx.Frame) {
+			return local, peers
+		},
+	})
+	require.Equal(GateDecision{}, gate.Check())
+	require.True(gate.Check().Open())
+
+	buffered = cfg.MaxBuffered
+	backlog = cfg.MaxFetcherBacklog
+	local, peers = 5, 5+cfg.MaxFrameLag
+	require.True(gate.Check().Open())
+
+	peers++
+	require.Equal(GateDecision{Reason: GateFrameLag, Err: ErrFrameLag}, gate.Check())
+	backlog++
+	require.Equal(GateDecision{Reason: GateFetcherBacklog, Err: ErrFetcherBacklog}, gate.Check())
+	buffered.Size++
+	require.Equal(GateDecision{Reason: GateBuffered, Err: ErrEventsBuffered}, gate.Check())
+	buffered.Size--
+	buffered.Num++
+	require.Equal(GateBuffered, gate.Check().Reason)
+	overloaded = true
+	d := gate.Check()
+	require.Equal(GateDecision{Reason: GateOverloaded, Err: ErrEventsOverloaded}, d)
+	require.False(d.Open())
+	require.Equal("overloaded", d.Reason.String())
+
+	// nil signals aren't checked
+	require.True(NewHealthGate(cfg, GateSignals{}).Check().Open())
+	// the first closed gate decides, the next ones aren't checked
+	overloadedCall = 0
+	d = AllGates(FetcherBacklogGate(func() int { return backlog }, 0), OverloadedGate(func() bool {
+		overloadedCall++
+		return true
+	})).Check()
+	require.Equal(GateFetcherBacklog, d.Reason)
+	require.Zero(overloadedCall)
+}
+
+func TestSyncedGate(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(1000, 0)
+	s := doublesign.SyncStatus{
+		PeersNum:  1,
+		Now:       now,
+		P2PSynced: now.Add(-time.Minute),
+	}
+	gate := SyncedGate(func() doublesign.SyncStatus {
+		return s
+	}, time.Hour)
+	require.Equal(GateDecision{GateNotSynced, doublesign.ErrJustP2PSynced, 59 * time.Minute}, gate.Check())
+	s.P2PSynced = now.Add(-time.Hour)
+	require.True(gate.Check().Open())
+}
+
+func TestEmitter_Gate(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(1)
+	w := newTestWorld(t, pos.EqualWeightValidators(nodes, 1))
+	em := w.newEmitter(nodes[0], nil)
+
+	overloaded := true
+	em.SetGate(OverloadedGate(func() bool {
+		return overloaded
+	}))
+	e, err := em.EmitEvent()
+	require.Nil(e)
+	require.Equal(ErrEventsOverloaded, err)
+
+	overloaded = false
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+}
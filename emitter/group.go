@@ -0,0 +1,171 @@
+package emitter
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/emitter/ancestor"
+	"github.com/Fantom-foundation/lachesis-base/emitter/doublesign"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+var (
+	ErrNoEmitters         = errors.New("no emitters in the group")
+	ErrDuplicateValidator = errors.New("validator ID is used by several emitters of the group")
+	ErrUnknownValidator   = errors.New("validator ID isn't emitted by the group")
+)
+
+// Group emits events of several local validator IDs from one process.
+// Each validator ID has its own Emitter, so self-parents, emission intervals, doublesign guards
+// and parents strategies are independent.
+// Emitters are called sequentially, so the callbacks aren't called concurrently.
+type Group struct {
+	emitters    []*Emitter
+	byValidator map[idx.ValidatorID]*Emitter
+
+	mu sync.Mutex
+
+	quit     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewGroup creates Group instance with an Emitter per config.
+// If identities isn't nil, the validator-specific fields of the sync status are taken from it,
+// so that an external self-event of one validator ID doesn't affect other validator IDs.
+func NewGroup(cfgs []Config, dagIndex ancestor.DagIndex, callback Callbacks, identities *doublesign.Identities) (*Group, error) {
+	if len(cfgs) == 0 {
+		return nil, ErrNoEmitters
+	}
+	g := &Group{
+		byValidator: make(map[idx.ValidatorID]*Emitter, len(cfgs)),
+		quit:        make(chan struct{}),
+	}
+	for _, cfg := range cfgs {
+		if g.byValidator[cfg.Validator] != nil {
+			return nil, ErrDuplicateValidator
+		}
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		emCallback := callback
+		if identities != nil {
+			validator := cfg.Validator
+			emCallback.GetSyncStatus = func() doublesign.SyncStatus {
+				return callback.GetSyncStatus().WithIdentity(identities.Get(validator))
+			}
+		}
+		em := New(cfg, dagIndex, emCallback)
+		g.emitters = append(g.emitters, em)
+		g.byValidator[cfg.Validator] = em
+	}
+	return g, nil
+}
+
+// Emitter returns the emitter of the validator ID, or nil if it's not in the group
+func (g *Group) Emitter(validator idx.ValidatorID) *Emitter {
+	return g.byValidator[validator]
+}
+
+// SetJournal sets a durable journal of emitted self-events for all the emitters.
+// Records of different validator IDs are independent.
+func (g *Group) SetJournal(journal *doublesign.Journal) {
+	for _, em := range g.emitters {
+		em.SetJournal(journal)
+	}
+}
+
+// SetGate sets a gate which is checked before emitting, for all the emitters.
+func (g *Group) SetGate(gate Gate) {
+	for _, em := range g.emitters {
+		em.SetGate(gate)
+	}
+}
+
+// OnEventConnected should be called for each connected event which wasn't emitted by this Group.
+func (g *Group) OnEventConnected(e dag.Event) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, em := range g.emitters {
+		em.OnEventConnected(e)
+	}
+}
+
+// EmitEvent emits a new event of the validator ID if it's the time to emit.
+// The emitted event is passed to other emitters of the group.
+func (g *Group) EmitEvent(validator idx.ValidatorID) (dag.Event, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.emitEvent(validator)
+}
+
+func (g *Group) emitEvent(validator idx.ValidatorID) (dag.Event, error) {
+	emitter := g.byValidator[validator]
+	if emitter == nil {
+		return nil, ErrUnknownValidator
+	}
+	e, err := emitter.EmitEvent()
+	if e == nil || err != nil {
+		return e, err
+	}
+	for _, em := range g.emitters {
+		if em != emitter {
+			em.OnEventConnected(e)
+		}
+	}
+	return e, nil
+}
+
+// EmitEvents tries to emit an event of each validator ID of the group.
+// Returns the emitted events.
+func (g *Group) EmitEvents() dag.Events {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var emitted dag.Events
+	for _, em := range g.emitters {
+		// errors mean that the event isn't emitted now, it'll be retried later
+		e, _ := g.emitEvent(em.cfg.Validator)
+		if e != nil {
+			emitted = append(emitted, e)
+		}
+	}
+	return emitted
+}
+
+// Start runs the emission loop, which tries to emit events every minimum MinEmitInterval of the emitters.
+func (g *Group) Start() {
+	period := g.emitters[0].cfg.MinEmitInterval
+	for _, em := range g.emitters {
+		if period > em.cfg.MinEmitInterval {
+			period = em.cfg.MinEmitInterval
+		}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				g.EmitEvents()
+			case <-g.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop interrupts the emission loop and waits until it's finished.
+// It's safe to call Stop several times.
+func (g *Group) Stop() {
+	g.stopOnce.Do(func() {
+		close(g.quit)
+	})
+	g.wg.Wait()
+}
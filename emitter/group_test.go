@@ -0,0 +1,118 @@
+package emitter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/abft"
+	"github.com/Fantom-foundation/lachesis-base/emitter/doublesign"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/utils/adapters"
+)
+
+func (w *testWorld) newGroup(validators []idx.ValidatorID, identities *doublesign.Identities) (*Group, error) {
+	cfgs := make([]Config, len(validators))
+	for i, v := range validators {
+		cfgs[i] = LiteConfig(v)
+	}
+	return NewGroup(cfgs, &adapters.VectorToDagIndexer{Index: w.dagIndex}, w.callbacks(nil), identities)
+}
+
+func TestGroup(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(4)
+	validators := pos.EqualWeightValidators(nodes, 1)
+	w := newTestWorld(t, validators)
+	// 3 validator IDs are run from one process, and a single validator from another one
+	g, err := w.newGroup(nodes[:3], nil)
+	require.NoError(err)
+	other := New(LiteConfig(nodes[3]), &adapters.VectorToDagIndexer{Index: w.dagIndex}, w.callbacks(nil))
+
+	for round := 0; round < 50; round++ {
+		w.now = w.now.Add(time.Millisecond)
+		for _, e := range g.EmitEvents() {
+			other.OnEventConnected(e)
+		}
+		e, err := other.EmitEvent()
+		require.NoError(err)
+		if e != nil {
+			g.OnEventConnected(e)
+		}
+	}
+	require.Greater(w.blocks, 5)
+
+	// every validator ID has its own self-parents chain
+	for _, v := range nodes {
+		e := w.GetEvent(w.lasts[v])
+		for e.SelfParent() != nil {
+			sp := w.GetEvent(*e.SelfParent())
+			require.Equal(v, sp.Creator())
+			require.Equal(e.Seq()-1, sp.Seq())
+			e = sp
+		}
+		require.Equal(v, e.Creator())
+		require.Equal(idx.Event(1), e.Seq())
+	}
+}
+
+func TestGroup_Isolation(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(3)
+	validators := pos.EqualWeightValidators(nodes, 1)
+	w := newTestWorld(t, validators)
+
+	_, err := w.newGroup([]idx.ValidatorID{nodes[0], nodes[0]}, nil)
+	require.Equal(ErrDuplicateValidator, err)
+	_, err = w.newGroup(nil, nil)
+	require.Equal(ErrNoEmitters, err)
+	invalid := LiteConfig(nodes[0])
+	invalid.MinEmitInterval = 0
+	_, err = NewGroup([]Config{invalid}, &adapters.VectorToDagIndexer{Index: w.dagIndex}, w.callbacks(nil), nil)
+	require.Equal(ErrInvalidEmitInterval, err)
+
+	w.status.P2PSynced = w.now.Add(-time.Hour)
+	identities := doublesign.NewIdentities()
+	g, err := w.newGroup(nodes[:2], identities)
+	require.NoError(err)
+	for _, v := range nodes[:2] {
+		g.Emitter(v).cfg.DoublesignProtection = time.Minute
+	}
+	_, err = g.EmitEvent(nodes[2])
+	require.Equal(ErrUnknownValidator, err)
+
+	// an external event of one validator ID doesn't prevent emission of another validator ID
+	identities.OnExternalSelfEvent(nodes[0], w.now, w.now)
+	_, err = g.EmitEvent(nodes[0])
+	require.Equal(doublesign.ErrSelfEventsOngoing, err)
+	e, err := g.EmitEvent(nodes[1])
+	require.NoError(err)
+	require.NotNil(e)
+	require.Equal(nodes[1], e.Creator())
+
+	// the emitted event is connected to another emitter of the group
+	w.now = w.now.Add(time.Minute)
+	e, err = g.EmitEvent(nodes[0])
+	require.NoError(err)
+	require.NotNil(e)
+	require.Nil(e.SelfParent())
+	require.Contains(e.Parents(), w.lasts[nodes[1]])
+
+	// an event of another validator ID is never used as a self-parent
+	em := g.Emitter(nodes[1])
+	em.callback.GetLastEvent = func(epoch idx.Epoch, _ idx.ValidatorID) *hash.Event {
+		require.Equal(abft.FirstEpoch, epoch)
+		last := w.lasts[nodes[0]]
+		return &last
+	}
+	w.now = w.now.Add(time.Minute)
+	e, err = g.EmitEvent(nodes[1])
+	require.Nil(e)
+	require.Equal(ErrForeignSelfParent, err)
+}
@@ -0,0 +1,86 @@
+package emitter
+
+import (
+	"math"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+// EmissionState describes a candidate self-event
+type EmissionState struct {
+	// FrameAdvance is true if the event has a higher frame than its self-parent
+	FrameAdvance bool
+	// MedianSeqs are QuorumIndexer.GetGlobalMedianSeqs
+	MedianSeqs []idx.Event
+	// SelfParentSeqs are QuorumIndexer.GetSelfParentSeqs
+	SelfParentSeqs []idx.Event
+	// ParentsSeqs are the highest seqs of each validator observed by the event parents
+	ParentsSeqs []idx.Event
+	// PayloadBacklog is the number of payload items waiting to be emitted
+	PayloadBacklog int
+}
+
+// IntervalController calculates the interval between the previous self-event and a candidate self-event.
+// The interval is short if the event would advance a frame, observe new events which aren't yet
+// observed by the quorum, or carry a large payload backlog. Otherwise, the interval is long.
+type IntervalController struct {
+	min, max       time.Duration
+	payloadBacklog int
+	validators     *pos.Validators
+}
+
+// NewIntervalController creates IntervalController instance.
+func NewIntervalController(cfg Config, validators *pos.Validators) *IntervalController {
+	return &IntervalController{
+		min:            cfg.MinEmitInterval,
+		max:            cfg.MaxEmitInterval,
+		payloadBacklog: cfg.PayloadBacklog,
+		validators:     validators,
+	}
+}
+
+// Progress returns the stake share of validators, which new events the candidate event would observe,
+// i.e. events which aren't observed by the self-parent nor by the quorum
+func (c *IntervalController) Progress(s EmissionState) float64 {
+	var progress pos.Weight
+	for i, seq := range s.ParentsSeqs {
+		if seq > s.MedianSeqs[i] && seq > s.SelfParentSeqs[i] {
+			progress += c.validators.GetWeightByIdx(idx.Validator(i))
+		}
+	}
+	return float64(progress) / float64(c.validators.TotalWeight())
+}
+
+// Urgency returns a number in [0, 1] range, which is 1 if the event should be emitted as soon as possible
+func (c *IntervalController) Urgency(s EmissionState) float64 {
+	if s.FrameAdvance {
+		return 1
+	}
+	urgency := c.Progress(s)
+	if s.PayloadBacklog > 0 {
+		backlog := 1.0
+		if c.payloadBacklog > s.PayloadBacklog {
+			backlog = float64(s.PayloadBacklog) / float64(c.payloadBacklog)
+		}
+		urgency = math.Max(urgency, backlog)
+	}
+	return urgency
+}
+
+// Interval returns the minimum interval between the previous self-event and the candidate event.
+// The interval is interpolated between MinEmitInterval and MaxEmitInterval geometrically,
+// so that each step of urgency changes the interval by the same ratio.
+func (c *IntervalController) Interval(s EmissionState) time.Duration {
+	urgency := c.Urgency(s)
+	if urgency >= 1 || c.min >= c.max {
+		return c.min
+	}
+	min := math.Max(float64(c.min), 1)
+	interval := min * math.Pow(float64(c.max)/min, 1-urgency)
+	if interval > float64(c.max) {
+		return c.max
+	}
+	return time.Duration(interval)
+}
@@ -0,0 +1,73 @@
+package emitter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+func TestIntervalController(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(4)
+	validators := pos.ArrayToValidators(nodes, []pos.Weight{5, 1, 1, 1})
+	cfg := DefaultConfig(nodes[0])
+	cfg.MinEmitInterval = time.Second
+	cfg.MaxEmitInterval = 100 * time.Second
+	cfg.PayloadBacklog = 10
+	c := NewIntervalController(cfg, validators)
+
+	idle := EmissionState{
+		MedianSeqs:     []idx.Event{5, 5, 5, 5},
+		SelfParentSeqs: []idx.Event{5, 6, 5, 5},
+		ParentsSeqs:    []idx.Event{5, 6, 5, 5},
+	}
+	require.Equal(0.0, c.Progress(idle))
+	require.Equal(cfg.MaxEmitInterval, c.Interval(idle))
+
+	// frame progress
+	s := idle
+	s.FrameAdvance = true
+	require.Equal(cfg.MinEmitInterval, c.Interval(s))
+
+	// events which are already observed by the quorum or by the self-parent aren't a progress
+	s = idle
+	s.MedianSeqs = []idx.Event{7, 5, 5, 5}
+	s.SelfParentSeqs = []idx.Event{5, 6, 7, 5}
+	s.ParentsSeqs = []idx.Event{6, 6, 6, 5}
+	require.Equal(0.0, c.Progress(s))
+
+	// interval shrinks with the stake of validators which new events are observed
+	s = idle
+	s.ParentsSeqs = []idx.Event{5, 6, 6, 5}
+	require.Equal(1.0/8, c.Progress(s))
+	small := c.Interval(s)
+	s.ParentsSeqs = []idx.Event{6, 6, 6, 5}
+	require.Equal(6.0/8, c.Progress(s))
+	large := c.Interval(s)
+	require.Less(int64(cfg.MinEmitInterval), int64(large))
+	require.Less(int64(large), int64(small))
+	require.Less(int64(small), int64(cfg.MaxEmitInterval))
+	s.ParentsSeqs = []idx.Event{6, 6, 6, 6}
+	require.Equal(7.0/8, c.Progress(s))
+	require.Less(int64(c.Interval(s)), int64(large))
+
+	// payload backlog
+	s = idle
+	s.PayloadBacklog = cfg.PayloadBacklog / 2
+	require.Equal(10*time.Second, c.Interval(s).Round(time.Millisecond))
+	s.PayloadBacklog = cfg.PayloadBacklog
+	require.Equal(cfg.MinEmitInterval, c.Interval(s))
+	s.PayloadBacklog = cfg.PayloadBacklog * 2
+	require.Equal(cfg.MinEmitInterval, c.Interval(s))
+
+	// the largest urgency wins
+	s.PayloadBacklog = 1
+	s.ParentsSeqs = []idx.Event{6, 6, 6, 5}
+	require.Equal(large, c.Interval(s))
+}
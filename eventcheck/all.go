@@ -3,6 +3,9 @@ package eventcheck
 import (
 	"github.com/Fantom-foundation/lachesis-base/eventcheck/basiccheck"
 	"github.com/Fantom-foundation/lachesis-base/eventcheck/epochcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/forkcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/framecheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/limitscheck"
 	"github.com/Fantom-foundation/lachesis-base/eventcheck/parentscheck"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
 )
@@ -12,18 +15,64 @@ type Checkers struct {
 	Basiccheck   *basiccheck.Checker
 	Epochcheck   *epochcheck.Checker
 	Parentscheck *parentscheck.Checker
+	// Limitscheck is optional, the structural limits aren't checked if it's nil
+	Limitscheck *limitscheck.Checker
+	// Forkcheck is optional, forks aren't detected if it's nil
+	Forkcheck *forkcheck.Checker
+	// Framecheck is optional, the claimed frame isn't prechecked if it's nil
+	Framecheck *framecheck.Checker
 }
 
-// Validate runs all the checks except Lachesis-related
-func (v *Checkers) Validate(e dag.Event, parents dag.Events) error {
+// StagedValidator performs the checks in two stages: the checks which don't require parents,
+// and the checks which require the parents list
+type StagedValidator interface {
+	ValidateParentless(e dag.Event) error
+	ValidateParents(e dag.Event, parents dag.Events) error
+}
+
+// ValidateParentless runs the checks which don't require parents
+func (v *Checkers) ValidateParentless(e dag.Event) error {
 	if err := v.Basiccheck.Validate(e); err != nil {
 		return err
 	}
 	if err := v.Epochcheck.Validate(e); err != nil {
 		return err
 	}
+	if v.Limitscheck != nil {
+		if err := v.Limitscheck.Validate(e); err != nil {
+			return err
+		}
+	}
+	if v.Forkcheck != nil {
+		if err := v.Forkcheck.Validate(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateParents runs the checks which require the parents list
+func (v *Checkers) ValidateParents(e dag.Event, parents dag.Events) error {
 	if err := v.Parentscheck.Validate(e, parents); err != nil {
 		return err
 	}
+	if v.Limitscheck != nil {
+		if err := v.Limitscheck.ValidateParents(e, parents); err != nil {
+			return err
+		}
+	}
+	if v.Framecheck != nil {
+		if err := v.Framecheck.Validate(e, parents); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// Validate runs all the checks except Lachesis-related
+func (v *Checkers) Validate(e dag.Event, parents dag.Events) error {
+	if err := v.ValidateParentless(e); err != nil {
+		return err
+	}
+	return v.ValidateParents(e, parents)
+}
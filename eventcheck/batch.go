@@ -0,0 +1,122 @@
+package eventcheck
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+)
+
+var (
+	ErrUnknownParents = errors.New("event parents are unknown")
+	ErrInvalidParent  = errors.New("event parent is invalid")
+)
+
+// batchChunk is the number of events which a worker takes at once
+const batchChunk = 64
+
+// BatchValidator validates batches of events on a pool of workers
+type BatchValidator struct {
+	checkers StagedValidator
+	workers  int
+}
+
+// NewBatchValidator creates BatchValidator instance, checkers may be Checkers or Registry.
+func NewBatchValidator(checkers StagedValidator, workers int) *BatchValidator {
+	if workers < 1 {
+		workers = 1
+	}
+	return &BatchValidator{
+		checkers: checkers,
+		workers:  workers,
+	}
+}
+
+// parallel calls fn for each index in [0, n) on the workers
+func (v *BatchValidator) parallel(n int, fn func(i int)) {
+	var next int64
+	wg := sync.WaitGroup{}
+	for w := 0; w < v.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				start := int(atomic.AddInt64(&next, batchChunk)) - batchChunk
+				if start >= n {
+					return
+				}
+				end := start + batchChunk
+				if end > n {
+					end = n
+				}
+				for i := start; i < end; i++ {
+					fn(i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// ValidateBatch runs all the checks, and returns the errors in the order of events.
+// The checks which don't require parents are performed in parallel first. Then the parents of each event
+// are taken from the batch or from getEvent, and the checks which require parents are performed in parallel.
+// Events with unknown parents fail with ErrUnknownParents, events with an invalid parent from the batch
+// fail with ErrInvalidParent.
+func (v *BatchValidator) ValidateBatch(events dag.Events, getEvent func(hash.Event) dag.Event) []error {
+	errs := make([]error, len(events))
+
+	// parentless checks
+	v.parallel(len(events), func(i int) {
+		errs[i] = v.checkers.ValidateParentless(events[i])
+	})
+
+	// parents checks
+	positions := make(map[hash.Event]int, len(events))
+	for i, e := range events {
+		positions[e.ID()] = i
+	}
+	v.parallel(len(events), func(i int) {
+		if errs[i] != nil {
+			return
+		}
+		e := events[i]
+		parents := make(dag.Events, len(e.Parents()))
+		for j, p := range e.Parents() {
+			if pos, ok := positions[p]; ok {
+				parents[j] = events[pos]
+			} else {
+				parents[j] = getEvent(p)
+			}
+			if parents[j] == nil {
+				errs[i] = ErrUnknownParents
+				return
+			}
+		}
+		errs[i] = v.checkers.ValidateParents(e, parents)
+	})
+
+	// propagate invalidity of parents from the batch.
+	// Valid parents have lower Lamport time, so events are processed after their parents
+	order := make([]int, 0, len(events))
+	for i := range events {
+		if errs[i] == nil {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return events[order[a]].Lamport() < events[order[b]].Lamport()
+	})
+	for _, i := range order {
+		for _, p := range events[i].Parents() {
+			if pos, ok := positions[p]; ok && errs[pos] != nil {
+				errs[i] = ErrInvalidParent
+				break
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,176 @@
+package eventcheck
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/basiccheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/epochcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/parentscheck"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+)
+
+type testReader struct {
+	validators *pos.Validators
+	epoch      idx.Epoch
+}
+
+func (r *testReader) GetEpochValidators() (*pos.Validators, idx.Epoch) {
+	return r.validators, r.epoch
+}
+
+func newTestCheckers(validators *pos.Validators) *Checkers {
+	return &Checkers{
+		Basiccheck:   basiccheck.New(),
+		Epochcheck:   epochcheck.New(&testReader{validators, 1}),
+		Parentscheck: parentscheck.New(),
+	}
+}
+
+func genTestBatch(validatorsNum, eventsNum int) (*pos.Validators, dag.Events) {
+	nodes := tdag.GenNodes(validatorsNum)
+	var events dag.Events
+	_ = tdag.ForEachRandEvent(nodes, eventsNum/validatorsNum, 3, rand.New(rand.NewSource(0)), tdag.ForEachEvent{
+		Build: func(e dag.MutableEvent, name string) error {
+			e.SetEpoch(1)
+			e.SetFrame(1)
+			return nil
+		},
+		Process: func(e dag.Event, name string) {
+			events = append(events, e)
+		},
+	})
+	return pos.EqualWeightValidators(nodes, 1), events
+}
+
+func TestBatchValidator(t *testing.T) {
+	require := require.New(t)
+
+	validators, events := genTestBatch(5, 500)
+	checkers := newTestCheckers(validators)
+
+	// the first events are known, the rest are validated in a shuffled batch
+	known := make(map[hash.Event]dag.Event)
+	for _, e := range events[:100] {
+		known[e.ID()] = e
+	}
+	getEvent := func(id hash.Event) dag.Event {
+		return known[id]
+	}
+	batch := make(dag.Events, 0, len(events)-100)
+	for _, i := range rand.New(rand.NewSource(1)).Perm(len(events) - 100) {
+		batch = append(batch, events[100+i])
+	}
+
+	for _, workers := range []int{1, 4} {
+		errs := NewBatchValidator(checkers, workers).ValidateBatch(batch, getEvent)
+		require.Len(errs, len(batch))
+		for i, err := range errs {
+			require.NoError(err, i)
+		}
+	}
+
+	// invalid events and their descendants
+	unknown := events[99].ID()
+	delete(known, unknown)
+	var candidates []*tdag.TestEvent
+	for _, e := range batch {
+		if !e.Parents().Set().Contains(unknown) {
+			candidates = append(candidates, e.(*tdag.TestEvent))
+		}
+	}
+	invalid := map[hash.Event]error{}
+	wrongEpoch := candidates[0]
+	wrongEpoch.SetEpoch(2)
+	invalid[wrongEpoch.ID()] = epochcheck.ErrNotRelevant
+	wrongLamport := candidates[1]
+	wrongLamport.SetLamport(wrongLamport.Lamport() + 1)
+	invalid[wrongLamport.ID()] = parentscheck.ErrWrongLamport
+
+	// an event must fail if it has an invalid or unknown ancestor in the batch
+	byID := make(map[hash.Event]dag.Event, len(events))
+	for _, e := range events {
+		byID[e.ID()] = e
+	}
+	failed := make(map[hash.Event]bool)
+	var mustFail func(id hash.Event) bool
+	mustFail = func(id hash.Event) bool {
+		if res, ok := failed[id]; ok {
+			return res
+		}
+		_, res := invalid[id]
+		res = res || id == unknown
+		for _, p := range byID[id].Parents() {
+			if _, ok := known[p]; !ok {
+				res = mustFail(p) || res
+			}
+		}
+		failed[id] = res
+		return res
+	}
+
+	errs := NewBatchValidator(checkers, 4).ValidateBatch(batch, getEvent)
+	for i, e := range batch {
+		if exp, ok := invalid[e.ID()]; ok {
+			require.Equal(exp, errs[i])
+		} else if e.Parents().Set().Contains(unknown) {
+			require.Equal(ErrUnknownParents, errs[i])
+		} else if mustFail(e.ID()) {
+			require.Error(errs[i], e.String())
+		} else {
+			require.NoError(errs[i], e.String())
+		}
+	}
+}
+
+func BenchmarkBatchValidator(b *testing.B) {
+	validators, events := genTestBatch(100, 10000)
+	checkers := newTestCheckers(validators)
+	getEvent := func(id hash.Event) dag.Event {
+		return nil
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			known := make(map[hash.Event]dag.Event, len(events))
+			for _, e := range events {
+				parents := make(dag.Events, len(e.Parents()))
+				for j, p := range e.Parents() {
+					parents[j] = known[p]
+				}
+				if err := checkers.Validate(e, parents); err != nil {
+					b.Fatal(err)
+				}
+				known[e.ID()] = e
+			}
+		}
+		b.ReportMetric(float64(len(events)*b.N)/b.Elapsed().Seconds(), "events/s")
+	})
+	workersSet := []int{1, 4}
+	if runtime.NumCPU() > 4 {
+		workersSet = append(workersSet, runtime.NumCPU())
+	}
+	for _, workers := range workersSet {
+		b.Run(fmt.Sprintf("batch/%d workers", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			v := NewBatchValidator(checkers, workers)
+			for i := 0; i < b.N; i++ {
+				for _, err := range v.ValidateBatch(events, getEvent) {
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(len(events)*b.N)/b.Elapsed().Seconds(), "events/s")
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package forkcheck
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+// ForkEvidence is the error which carries two events of the same creator with the same seq
+type ForkEvidence struct {
+	Creator idx.ValidatorID
+	Epoch   idx.Epoch
+	Seq     idx.Event
+	// Existing is the event which is already indexed
+	Existing hash.Event
+	// Conflicting is the validated event
+	Conflicting hash.Event
+}
+
+// Error implements error interface
+func (ev *ForkEvidence) Error() string {
+	return fmt.Sprintf("fork of validator %d at epoch %d, seq %d: %s and %s", ev.Creator, ev.Epoch, ev.Seq, ev.Existing.String(), ev.Conflicting.String())
+}
+
+// OnFork is called with the evidence of each detected fork.
+// The returned error is the result of the check, so the event is still accepted if it returns nil.
+type OnFork func(evidence *ForkEvidence) error
+
+// RejectForks is the OnFork which rejects the forks
+func RejectForks(evidence *ForkEvidence) error {
+	return evidence
+}
+
+type creatorSeq struct {
+	creator idx.ValidatorID
+	seq     idx.Event
+}
+
+// Checker detects forks by an index of the events of the current epoch
+type Checker struct {
+	onFork OnFork
+
+	mu     sync.RWMutex
+	epoch  idx.Epoch
+	events map[creatorSeq]hash.Event
+}
+
+// New checker which detects a second event of the same creator with the same seq.
+// If onFork is nil, forks are rejected.
+func New(onFork OnFork) *Checker {
+	if onFork == nil {
+		onFork = RejectForks
+	}
+	return &Checker{
+		onFork: onFork,
+		events: make(map[creatorSeq]hash.Event),
+	}
+}
+
+// Add indexes the event. It should be called for each connected event.
+// The index is reset if the event is of a newer epoch.
+func (v *Checker) Add(e dag.Event) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if e.Epoch() < v.epoch {
+		return
+	}
+	if e.Epoch() > v.epoch {
+		v.reset(e.Epoch())
+	}
+	key := creatorSeq{e.Creator(), e.Seq()}
+	if _, ok := v.events[key]; !ok {
+		v.events[key] = e.ID()
+	}
+}
+
+// Reset clears the index, it should be called when a new epoch is sealed
+func (v *Checker) Reset(epoch idx.Epoch) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.reset(epoch)
+}
+
+func (v *Checker) reset(epoch idx.Epoch) {
+	v.epoch = epoch
+	v.events = make(map[creatorSeq]hash.Event)
+}
+
+// Validate event. Events which aren't of the indexed epoch aren't checked.
+// Only the indexed events are considered, so to detect forks within a batch of not yet connected events,
+// the event should be validated again right before it's connected, see dagprocessor.Processor.SetForkcheck.
+func (v *Checker) Validate(e dag.Event) error {
+	v.mu.RLock()
+	existing, ok := v.events[creatorSeq{e.Creator(), e.Seq()}]
+	epoch := v.epoch
+	v.mu.RUnlock()
+
+	if !ok || e.Epoch() != epoch || existing == e.ID() {
+		return nil
+	}
+	return v.onFork(&ForkEvidence{
+		Creator:     e.Creator(),
+		Epoch:       epoch,
+		Seq:         e.Seq(),
+		Existing:    existing,
+		Conflicting: e.ID(),
+	})
+}
@@ -0,0 +1,72 @@
+package forkcheck
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+func testEvent(epoch idx.Epoch, creator idx.ValidatorID, seq idx.Event, id byte) *tdag.TestEvent {
+	e := &tdag.TestEvent{}
+	e.SetEpoch(epoch)
+	e.SetCreator(creator)
+	e.SetSeq(seq)
+	e.SetLamport(idx.Lamport(seq))
+	e.SetID([24]byte{id})
+	return e
+}
+
+func TestChecker(t *testing.T) {
+	require := require.New(t)
+
+	a := testEvent(1, 1, 1, 1)
+	fork := testEvent(1, 1, 1, 2)
+	other := testEvent(1, 2, 1, 3)
+
+	v := New(nil)
+	require.NoError(v.Validate(a))
+	require.NoError(v.Validate(fork))
+	v.Add(a)
+	require.NoError(v.Validate(a))
+	require.NoError(v.Validate(other))
+
+	err := v.Validate(fork)
+	var evidence *ForkEvidence
+	require.True(errors.As(err, &evidence))
+	require.Equal(ForkEvidence{
+		Creator:     1,
+		Epoch:       1,
+		Seq:         1,
+		Existing:    a.ID(),
+		Conflicting: fork.ID(),
+	}, *evidence)
+
+	// the first indexed event is kept
+	v.Add(fork)
+	require.Equal(a.ID(), v.Validate(testEvent(1, 1, 1, 4)).(*ForkEvidence).Existing)
+
+	// new epoch
+	require.NoError(v.Validate(testEvent(2, 1, 1, 5)))
+	v.Add(testEvent(2, 1, 1, 5))
+	require.NoError(v.Validate(fork))
+	require.Error(v.Validate(testEvent(2, 1, 1, 6)))
+	v.Reset(3)
+	require.NoError(v.Validate(testEvent(2, 1, 1, 6)))
+}
+
+func TestChecker_Accept(t *testing.T) {
+	require := require.New(t)
+
+	var detected []*ForkEvidence
+	v := New(func(evidence *ForkEvidence) error {
+		detected = append(detected, evidence)
+		return nil
+	})
+	v.Add(testEvent(1, 1, 1, 1))
+	require.NoError(v.Validate(testEvent(1, 1, 1, 2)))
+	require.Len(detected, 1)
+}
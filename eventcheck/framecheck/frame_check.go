@@ -0,0 +1,53 @@
+package framecheck
+
+import (
+	"errors"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+)
+
+var (
+	ErrFrameBelowSelfParent = errors.New("event frame is lower than self-parent frame")
+	ErrFrameAboveParents    = errors.New("event frame is higher than next frame of parents")
+	ErrFirstEventFrame      = errors.New("first event of a validator isn't in the first frame")
+)
+
+// Checker performs cheap plausibility checks of the claimed frame, which require the parents list.
+// The exact frame is calculated by Lachesis, the checks only reject the events which cannot pass it.
+type Checker struct{}
+
+// New checker which performs plausibility checks of the claimed frame
+func New() *Checker {
+	return &Checker{}
+}
+
+// Validate event
+func (v *Checker) Validate(e dag.Event, parents dag.Events) error {
+	if len(e.Parents()) != len(parents) {
+		panic("framecheck: expected event's parents as an argument")
+	}
+
+	// the first event of a validator cannot be a root of a higher frame, because there're no roots in frame 0
+	if e.SelfParent() == nil {
+		if e.Frame() > 1 {
+			return ErrFirstEventFrame
+		}
+		return nil
+	}
+	// a non-root event has the frame of its self-parent, and a root has a higher frame
+	selfParentFrame := parents[0].Frame()
+	if e.Frame() < selfParentFrame {
+		return ErrFrameBelowSelfParent
+	}
+	if e.Frame() == selfParentFrame {
+		return nil
+	}
+	// a root of a frame observes the roots of the previous frame, so it has a parent from the previous frame or higher.
+	// The parent may be from a higher frame, if the roots are observed through it
+	for _, p := range parents {
+		if p.Frame()+1 >= e.Frame() {
+			return nil
+		}
+	}
+	return ErrFrameAboveParents
+}
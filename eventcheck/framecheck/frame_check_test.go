@@ -0,0 +1,57 @@
+package framecheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+func testEvent(creator idx.ValidatorID, seq idx.Event, frame idx.Frame, parents ...dag.Event) *tdag.TestEvent {
+	e := &tdag.TestEvent{}
+	e.SetEpoch(1)
+	e.SetCreator(creator)
+	e.SetSeq(seq)
+	e.SetFrame(frame)
+	ids := hash.Events{}
+	for _, p := range parents {
+		ids.Add(p.ID())
+	}
+	e.SetParents(ids)
+	e.SetID([24]byte{byte(creator), byte(seq), byte(frame)})
+	return e
+}
+
+func TestChecker(t *testing.T) {
+	a1 := testEvent(1, 1, 1)
+	a2 := testEvent(1, 2, 3, a1)
+	b3 := testEvent(2, 3, 4)
+
+	for _, tt := range []struct {
+		name    string
+		e       dag.Event
+		parents dag.Events
+		err     error
+	}{
+		{"first frame", testEvent(3, 1, 1), nil, nil},
+		{"skipped first frame", testEvent(3, 1, 2), nil, ErrFirstEventFrame},
+		{"first event below other parent", testEvent(3, 1, 1, b3), dag.Events{b3}, nil},
+		{"first event in next frame of other parent", testEvent(3, 1, 5, b3), dag.Events{b3}, ErrFirstEventFrame},
+		{"same frame", testEvent(1, 3, 3, a2), dag.Events{a2}, nil},
+		{"below other parent", testEvent(1, 3, 3, a2, b3), dag.Events{a2, b3}, nil},
+		{"below self-parent", testEvent(1, 3, 2, a2, b3), dag.Events{a2, b3}, ErrFrameBelowSelfParent},
+		{"next frame", testEvent(1, 3, 4, a2), dag.Events{a2}, nil},
+		{"skipped frame", testEvent(1, 3, 5, a2), dag.Events{a2}, ErrFrameAboveParents},
+		{"frame of other parent", testEvent(1, 3, 4, a2, b3), dag.Events{a2, b3}, nil},
+		{"next frame of other parent", testEvent(1, 3, 5, a2, b3), dag.Events{a2, b3}, nil},
+		{"skipped frame of other parent", testEvent(1, 3, 6, a2, b3), dag.Events{a2, b3}, ErrFrameAboveParents},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.err, New().Validate(tt.e, tt.parents))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package limitscheck
+
+import (
+	"errors"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+var (
+	ErrTooManyParents = errors.New("event has too many parents")
+	ErrTooBigSize     = errors.New("event size is too big")
+	ErrTooHighSeq     = errors.New("event seq is too high for an epoch")
+	ErrLamportGap     = errors.New("event Lamport time is too far from parents")
+	ErrFrameJump      = errors.New("event frame is too far from self-parent")
+)
+
+// Config is the structural limits of events. Zero value of a limit means it isn't checked.
+type Config struct {
+	// MaxParents is the maximum number of parents, including the self-parent
+	MaxParents int
+	// MaxSize is the maximum event size in bytes, see dag.Event.Size
+	MaxSize int
+	// MaxSeq is the maximum seq of an event. Seq starts from 1 in each epoch,
+	// so it's the maximum number of events of a validator in an epoch
+	MaxSeq idx.Event
+	// MaxLamportGap is the maximum difference between Lamport time of the event and the lowest Lamport time of its parents
+	MaxLamportGap idx.Lamport
+	// MaxFrameJump is the maximum difference between frame of the event and frame of its self-parent.
+	// The first event of a validator in an epoch has no self-parent, so it isn't limited. Its frame is checked by framecheck
+	MaxFrameJump idx.Frame
+}
+
+// DefaultConfig for livenet.
+func DefaultConfig() Config {
+	return Config{
+		MaxParents:    10,
+		MaxSize:       128 * 1024,
+		MaxSeq:        1e6,
+		MaxLamportGap: 1000,
+		MaxFrameJump:  100,
+	}
+}
+
+// Checker performs checks of the configured structural limits
+type Checker struct {
+	cfg Config
+}
+
+// New checker which performs checks of the configured structural limits
+func New(cfg Config) *Checker {
+	return &Checker{
+		cfg: cfg,
+	}
+}
+
+// Validate event, the checks don't require anything except event
+func (v *Checker) Validate(e dag.Event) error {
+	if v.cfg.MaxParents != 0 && len(e.Parents()) > v.cfg.MaxParents {
+		return ErrTooManyParents
+	}
+	if v.cfg.MaxSize != 0 && e.Size() > v.cfg.MaxSize {
+		return ErrTooBigSize
+	}
+	if v.cfg.MaxSeq != 0 && e.Seq() > v.cfg.MaxSeq {
+		return ErrTooHighSeq
+	}
+	return nil
+}
+
+// ValidateParents validates event, the checks require the parents list
+func (v *Checker) ValidateParents(e dag.Event, parents dag.Events) error {
+	if len(e.Parents()) != len(parents) {
+		panic("limitscheck: expected event's parents as an argument")
+	}
+
+	if v.cfg.MaxLamportGap != 0 {
+		for _, p := range parents {
+			if e.Lamport() > p.Lamport() && e.Lamport()-p.Lamport() > v.cfg.MaxLamportGap {
+				return ErrLamportGap
+			}
+		}
+	}
+	if v.cfg.MaxFrameJump != 0 && e.SelfParent() != nil {
+		selfParent := parents[0]
+		if e.Frame() > selfParent.Frame() && e.Frame()-selfParent.Frame() > v.cfg.MaxFrameJump {
+			return ErrFrameJump
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package limitscheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+func testEvent(creator idx.ValidatorID, seq idx.Event, frame idx.Frame, lamport idx.Lamport, parents ...dag.Event) *tdag.TestEvent {
+	e := &tdag.TestEvent{}
+	e.SetEpoch(1)
+	e.SetCreator(creator)
+	e.SetSeq(seq)
+	e.SetFrame(frame)
+	e.SetLamport(lamport)
+	ids := hash.Events{}
+	for _, p := range parents {
+		ids.Add(p.ID())
+	}
+	e.SetParents(ids)
+	e.SetID([24]byte{byte(creator), byte(seq), byte(lamport)})
+	return e
+}
+
+func TestChecker(t *testing.T) {
+	cfg := Config{
+		MaxParents:    3,
+		MaxSize:       200,
+		MaxSeq:        10,
+		MaxLamportGap: 5,
+		MaxFrameJump:  2,
+	}
+	a1 := testEvent(1, 1, 1, 1)
+	b1 := testEvent(2, 1, 1, 1)
+	c1 := testEvent(3, 1, 1, 1)
+	d1 := testEvent(4, 1, 1, 1)
+	a9 := testEvent(1, 9, 1, 9)
+	b5 := testEvent(2, 5, 1, 5)
+
+	for _, tt := range []struct {
+		name    string
+		e       dag.Event
+		parents dag.Events
+		err     error
+	}{
+		{"valid", testEvent(1, 10, 3, 10, a9, b5), dag.Events{a9, b5}, nil},
+		{"first event isn't limited", testEvent(1, 1, 7, 2, b1), dag.Events{b1}, nil},
+		{"parents", testEvent(1, 2, 1, 2, a1, b1, c1, d1), dag.Events{a1, b1, c1, d1}, ErrTooManyParents},
+		{"seq", testEvent(1, 11, 1, 11), nil, ErrTooHighSeq},
+		{"lamport gap", testEvent(1, 10, 1, 10, a9, b1, c1), dag.Events{a9, b1, c1}, ErrLamportGap},
+		{"max frame jump", testEvent(1, 10, 3, 10, a9), dag.Events{a9}, nil},
+		{"frame jump", testEvent(1, 10, 4, 10, a9), dag.Events{a9}, ErrFrameJump},
+		{"frame jump via other parent", testEvent(1, 10, 4, 10, a9, b5), dag.Events{a9, b5}, ErrFrameJump},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New(cfg)
+			err := v.Validate(tt.e)
+			if err == nil {
+				err = v.ValidateParents(tt.e, tt.parents)
+			}
+			require.Equal(t, tt.err, err)
+		})
+	}
+
+	// size
+	e := testEvent(1, 2, 1, 2, a1, b1, c1)
+	require.NoError(t, New(Config{MaxSize: e.Size()}).Validate(e))
+	require.Equal(t, ErrTooBigSize, New(Config{MaxSize: e.Size() - 1}).Validate(e))
+
+	// zero config checks nothing
+	require.NoError(t, New(Config{}).Validate(testEvent(1, 1e9, 1e6, 1e9, a1, b1, c1, d1)))
+	require.NoError(t, New(Config{}).ValidateParents(testEvent(1, 2, 1e6, 1e9, a1), dag.Events{a1}))
+}
@@ -8,4 +8,6 @@ var (
 	ErrAlreadyConnectedEvent = errors.New("event is connected already")
 	ErrSpilledEvent          = errors.New("event is spilled")
 	ErrDuplicateEvent        = errors.New("event is duplicated")
+	// ErrDroppedEvent is the error of the events which are released because the node clears its buffers
+	ErrDroppedEvent = errors.New("event is dropped")
 )
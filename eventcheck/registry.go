@@ -0,0 +1,233 @@
+package eventcheck
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+)
+
+var (
+	ErrDuplicateRule = errors.New("checker rule with the same name is already registered")
+	ErrNilCheck      = errors.New("checker rule has no check function")
+)
+
+// Stage is a stage of events validation
+type Stage int
+
+const (
+	// StageParentless is the stage of checks which don't require parents
+	StageParentless Stage = iota
+	// StageParents is the stage of checks which require parents, it's performed after StageParentless
+	StageParents
+)
+
+// ParentlessCheck is a check which doesn't require parents
+type ParentlessCheck func(e dag.Event) error
+
+// ParentsCheck is a check which requires the parents list
+type ParentsCheck func(e dag.Event, parents dag.Events) error
+
+// RuleStats is the counters of a checker rule
+type RuleStats struct {
+	Name   string
+	Stage  Stage
+	Passed uint64
+	Failed uint64
+	// Time is the total time spent in the rule
+	Time time.Duration
+}
+
+type rule struct {
+	name  string
+	stage Stage
+	order int
+
+	parentless ParentlessCheck
+	parents    ParentsCheck
+
+	passed uint64
+	failed uint64
+	time   int64
+}
+
+func (r *rule) check(e dag.Event, parents dag.Events) error {
+	start := time.Now()
+	var err error
+	if r.stage == StageParentless {
+		err = r.parentless(e)
+	} else {
+		err = r.parents(e, parents)
+	}
+	atomic.AddInt64(&r.time, int64(time.Since(start)))
+	if err != nil {
+		atomic.AddUint64(&r.failed, 1)
+	} else {
+		atomic.AddUint64(&r.passed, 1)
+	}
+	return err
+}
+
+func (r *rule) stats() RuleStats {
+	return RuleStats{
+		Name:   r.name,
+		Stage:  r.stage,
+		Passed: atomic.LoadUint64(&r.passed),
+		Failed: atomic.LoadUint64(&r.failed),
+		Time:   time.Duration(atomic.LoadInt64(&r.time)),
+	}
+}
+
+// Registry is a pipeline of named checker rules, which apps may extend with their own checks.
+// Rules of each stage are performed in ascending order, rules with equal order are performed in the order of registration.
+// If ShortCircuit is true, validation stops on the first failed rule. Otherwise, all the rules are performed
+// (so that their counters are updated) and the first error is returned.
+// It's safe to validate events concurrently.
+type Registry struct {
+	ShortCircuit bool
+
+	mu     sync.RWMutex
+	rules  map[string]*rule
+	stages [2][]*rule
+}
+
+// NewRegistry creates an empty Registry instance.
+func NewRegistry(shortCircuit bool) *Registry {
+	return &Registry{
+		ShortCircuit: shortCircuit,
+		rules:        make(map[string]*rule),
+	}
+}
+
+// NewDefaultRegistry creates Registry instance with the rules of the checkers.
+// Default rules have orders multiple of 100, so that app rules may be placed between them.
+func NewDefaultRegistry(checkers *Checkers) *Registry {
+	r := NewRegistry(true)
+	_ = r.RegisterParentless("basiccheck", 100, checkers.Basiccheck.Validate)
+	_ = r.RegisterParentless("epochcheck", 200, checkers.Epochcheck.Validate)
+	if checkers.Limitscheck != nil {
+		_ = r.RegisterParentless("limitscheck", 300, checkers.Limitscheck.Validate)
+	}
+	if checkers.Forkcheck != nil {
+		_ = r.RegisterParentless("forkcheck", 400, checkers.Forkcheck.Validate)
+	}
+	_ = r.RegisterParents("parentscheck", 100, checkers.Parentscheck.Validate)
+	if checkers.Limitscheck != nil {
+		_ = r.RegisterParents("limitscheck/parents", 200, checkers.Limitscheck.ValidateParents)
+	}
+	if checkers.Framecheck != nil {
+		_ = r.RegisterParents("framecheck", 300, checkers.Framecheck.Validate)
+	}
+	return r
+}
+
+func (r *Registry) register(ru *rule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rules[ru.name] != nil {
+		return ErrDuplicateRule
+	}
+	r.rules[ru.name] = ru
+	// copy on write, so that validation doesn't hold the lock while checking
+	stage := append(append(make([]*rule, 0, len(r.stages[ru.stage])+1), r.stages[ru.stage]...), ru)
+	sort.SliceStable(stage, func(i, j int) bool {
+		return stage[i].order < stage[j].order
+	})
+	r.stages[ru.stage] = stage
+	return nil
+}
+
+// RegisterParentless registers a named rule of the parentless stage
+func (r *Registry) RegisterParentless(name string, order int, check ParentlessCheck) error {
+	if check == nil {
+		return ErrNilCheck
+	}
+	return r.register(&rule{
+		name:       name,
+		stage:      StageParentless,
+		order:      order,
+		parentless: check,
+	})
+}
+
+// RegisterParents registers a named rule of the with-parents stage
+func (r *Registry) RegisterParents(name string, order int, check ParentsCheck) error {
+	if check == nil {
+		return ErrNilCheck
+	}
+	return r.register(&rule{
+		name:    name,
+		stage:   StageParents,
+		order:   order,
+		parents: check,
+	})
+}
+
+func (r *Registry) validate(stage Stage, e dag.Event, parents dag.Events) error {
+	r.mu.RLock()
+	rules := r.stages[stage]
+	r.mu.RUnlock()
+
+	var first error
+	for _, ru := range rules {
+		if err := ru.check(e, parents); err != nil && first == nil {
+			first = err
+			if r.ShortCircuit {
+				return err
+			}
+		}
+	}
+	return first
+}
+
+// ValidateParentless runs the rules of the parentless stage
+func (r *Registry) ValidateParentless(e dag.Event) error {
+	return r.validate(StageParentless, e, nil)
+}
+
+// ValidateParents runs the rules of the with-parents stage
+func (r *Registry) ValidateParents(e dag.Event, parents dag.Events) error {
+	return r.validate(StageParents, e, parents)
+}
+
+// Validate runs the rules of all the stages
+func (r *Registry) Validate(e dag.Event, parents dag.Events) error {
+	err := r.ValidateParentless(e)
+	if err != nil && r.ShortCircuit {
+		return err
+	}
+	if err2 := r.ValidateParents(e, parents); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// Stats returns the counters of the rule, or false if rule isn't registered
+func (r *Registry) Stats(name string) (RuleStats, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ru := r.rules[name]
+	if ru == nil {
+		return RuleStats{}, false
+	}
+	return ru.stats(), true
+}
+
+// AllStats returns the counters of all the rules, in the order of validation
+func (r *Registry) AllStats() []RuleStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make([]RuleStats, 0, len(r.rules))
+	for _, stage := range r.stages {
+		for _, ru := range stage {
+			stats = append(stats, ru.stats())
+		}
+	}
+	return stats
+}
@@ -0,0 +1,99 @@
+package eventcheck
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/basiccheck"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+)
+
+func TestRegistry(t *testing.T) {
+	require := require.New(t)
+
+	errA := errors.New("a")
+	errB := errors.New("b")
+	var calls []string
+	check := func(name string, err error) ParentlessCheck {
+		return func(e dag.Event) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	r := NewRegistry(true)
+	require.NoError(r.RegisterParentless("c", 300, check("c", nil)))
+	require.NoError(r.RegisterParentless("a", 100, check("a", errA)))
+	require.NoError(r.RegisterParentless("b", 100, check("b", errB)))
+	require.NoError(r.RegisterParents("p", 0, func(e dag.Event, parents dag.Events) error {
+		calls = append(calls, "p")
+		return nil
+	}))
+	require.Equal(ErrDuplicateRule, r.RegisterParentless("a", 0, check("a", nil)))
+	require.Equal(ErrDuplicateRule, r.RegisterParents("a", 0, func(e dag.Event, parents dag.Events) error { return nil }))
+	require.Equal(ErrNilCheck, r.RegisterParentless("d", 0, nil))
+
+	e := &tdag.TestEvent{}
+
+	// short circuit
+	require.Equal(errA, r.Validate(e, nil))
+	require.Equal([]string{"a"}, calls)
+
+	// all the rules
+	calls = nil
+	r.ShortCircuit = false
+	require.Equal(errA, r.Validate(e, nil))
+	require.Equal([]string{"a", "b", "c", "p"}, calls)
+
+	stats, ok := r.Stats("a")
+	require.True(ok)
+	require.Equal(uint64(0), stats.Passed)
+	require.Equal(uint64(2), stats.Failed)
+	_, ok = r.Stats("unknown")
+	require.False(ok)
+
+	require.Equal([]RuleStats{
+		{Name: "a", Stage: StageParentless, Failed: 2},
+		{Name: "b", Stage: StageParentless, Failed: 1},
+		{Name: "c", Stage: StageParentless, Passed: 1},
+		{Name: "p", Stage: StageParents, Passed: 1},
+	}, withoutTime(r.AllStats()))
+}
+
+func withoutTime(stats []RuleStats) []RuleStats {
+	for i := range stats {
+		stats[i].Time = 0
+	}
+	return stats
+}
+
+func TestRegistry_Default(t *testing.T) {
+	require := require.New(t)
+
+	validators, events := genTestBatch(5, 500)
+	checkers := newTestCheckers(validators)
+	r := NewDefaultRegistry(checkers)
+
+	wrongSeq := events[len(events)-1].(*tdag.TestEvent)
+	wrongSeq.SetSeq(0)
+
+	errs := NewBatchValidator(r, 4).ValidateBatch(events, func(hash.Event) dag.Event { return nil })
+	for i, err := range errs[:len(events)-1] {
+		require.NoError(err, i)
+	}
+	require.Equal(basiccheck.ErrNotInited, errs[len(events)-1])
+
+	stats, ok := r.Stats("basiccheck")
+	require.True(ok)
+	require.Equal(uint64(len(events)-1), stats.Passed)
+	require.Equal(uint64(1), stats.Failed)
+	stats, ok = r.Stats("parentscheck")
+	require.True(ok)
+	require.Equal(uint64(len(events)-1), stats.Passed)
+	require.Equal(uint64(0), stats.Failed)
+	require.Len(r.AllStats(), 3)
+}
@@ -19,6 +19,16 @@ type (
 		peer     string
 		err      error
 		released bool
+		// overflowed is true if the event is taken from the disk tier
+		overflowed bool
+
+		// missing is the number of parents which aren't connected, when the event is indexed in deps
+		missing int
+
+		// children is the number of waiting children, and the positions are of the heaps of evictionIndex
+		children   int
+		lowestPos  int
+		highestPos int
 	}
 
 	// Callback is a set of EventsBuffer()'s args.
@@ -28,6 +38,13 @@ type (
 		Get      func(hash.Event) dag.Event
 		Exists   func(hash.Event) bool
 		Check    func(e dag.Event, parents dag.Events) error
+		// HighestLamport is optional, it's used to prioritize events for eviction
+		HighestLamport func() idx.Lamport
+
+		// Overflowed is optional, it's called when the event is moved into the disk tier, so it doesn't occupy the memory.
+		// Such event is released by ReleasedOverflowed when it leaves the disk tier for good, or by Released if it's nil
+		Overflowed         func(e dag.Event, peer string)
+		ReleasedOverflowed func(e dag.Event, peer string, err error)
 	}
 )
 
@@ -36,20 +53,36 @@ type EventsBuffer struct {
 	callback    Callback
 	mu          sync.Mutex
 
+	// deps is missing parent -> incomplete events which wait for it
 	deps map[hash.Event]map[hash.Event]bool
+	// evictions orders incomplete events for eviction
+	evictions *evictionIndex
 
 	limit dag.Metric
+
+	overflow *Overflow
+	// reinjected are the events from the disk tier, which are pushed after the current event
+	reinjected []*event
 }
 
 func New(limit dag.Metric, callback Callback) *EventsBuffer {
 	buf := &EventsBuffer{
-		callback: callback,
-		limit:    limit,
+		callback:  callback,
+		limit:     limit,
+		deps:      make(map[hash.Event]map[hash.Event]bool),
+		evictions: newEvictionIndex(),
 	}
 	buf.incompletes, _ = wlru.New(math.MaxInt32, math.MaxInt32)
 	return buf
 }
 
+// SetOverflow sets a disk tier, which stores the spilled events until their parents are connected.
+// The stored events are released once they leave the disk tier for good, i.e. after re-injection or when they are erased.
+// It should be called before the buffer is used.
+func (buf *EventsBuffer) SetOverflow(overflow *Overflow) {
+	buf.overflow = overflow
+}
+
 func (buf *EventsBuffer) PushEvent(de dag.Event, peer string) (complete bool) {
 	e := &event{
 		event: de,
@@ -59,54 +92,179 @@ func (buf *EventsBuffer) PushEvent(de dag.Event, peer string) (complete bool) {
 	buf.mu.Lock()
 	defer buf.mu.Unlock()
 
-	if _, ok := buf.incompletes.Peek(e.event.ID()); ok {
+	if _, ok := buf.incompletes.Peek(e.event.ID()); ok || buf.overflow != nil && buf.overflow.Contains(e.event.ID()) {
 		// duplicate
 		buf.dropEvent(e, eventcheck.ErrDuplicateEvent)
 		buf.releaseEvent(e)
 		return false
 	}
-	complete = buf.pushEvent(e, nil, false)
+	complete = buf.pushEvent(e, false)
+	buf.pushReinjected()
 	buf.spillIncompletes(buf.limit)
 	return complete
 }
 
-func (buf *EventsBuffer) pushEvent(e *event, incompleteEventsList []*event, recheck bool) bool {
-	if buf.callback.Exists(e.event.ID()) {
-		buf.incompletes.Remove(e.event.ID())
+// pushEvent processes the event if it's complete, or stores it as incomplete otherwise.
+// The children which become complete are processed in the same call, without recursion.
+func (buf *EventsBuffer) pushEvent(e *event, recheck bool) bool {
+	complete, ready := buf.tryEvent(e, recheck, nil)
+	for len(ready) != 0 {
+		child := ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+		_, ready = buf.tryEvent(child, true, ready)
+	}
+	return complete
+}
+
+// tryEvent processes the event if it's complete, or stores it as incomplete otherwise.
+// The children which have no missing parents after the event is processed are appended to ready.
+func (buf *EventsBuffer) tryEvent(e *event, recheck bool, ready []*event) (bool, []*event) {
+	eHash := e.event.ID()
+	if buf.callback.Exists(eHash) {
+		buf.removeIncomplete(e)
 		if !recheck {
 			buf.dropEvent(e, eventcheck.ErrAlreadyConnectedEvent)
 		}
 		buf.releaseEvent(e)
-		return false
+		// the event may be connected not by the buffer, so its children may wait for it
+		return false, buf.onConnected(eHash, ready)
 	}
-	parents := buf.completeEventParents(e)
-	if parents == nil {
+	parents, missing := buf.resolveEventParents(e)
+	if len(missing) != 0 {
 		if !recheck {
-			buf.incompletes.Add(e.event.ID(), e, uint(e.event.Size()))
+			buf.incompletes.Add(eHash, e, uint(e.event.Size()))
+			buf.evictions.Add(e, len(buf.deps[eHash]))
+		} else {
+			buf.unindexDeps(e)
 		}
-		return false
+		buf.indexDeps(e, missing)
+		return false, ready
 	}
 
 	ok := buf.processCompleteEvent(e, parents)
 	buf.releaseEvent(e)
+	buf.removeIncomplete(e)
 
 	if ok {
-		// now child events may become complete, check it again
-		eHash := e.event.ID()
-		if incompleteEventsList == nil {
-			incompleteEventsList = buf.getIncompleteEventsList()
+		ready = buf.onConnected(eHash, ready)
+	}
+	return ok, ready
+}
+
+// onConnected takes the children which wait for the connected event.
+// The children which have no missing parents are appended to ready, the children from the disk tier are re-injected.
+func (buf *EventsBuffer) onConnected(id hash.Event, ready []*event) []*event {
+	for childID := range buf.deps[id] {
+		val, _ := buf.incompletes.Peek(childID)
+		if val == nil {
+			continue
+		}
+		child := val.(*event)
+		child.missing--
+		if child.missing == 0 {
+			ready = append(ready, child)
+		}
+	}
+	delete(buf.deps, id)
+	buf.takeOverflowed(id)
+	return ready
+}
+
+// OnConnected should be called for the events which are connected not by the buffer,
+// so that the buffered events which wait for them are processed
+func (buf *EventsBuffer) OnConnected(id hash.Event) {
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+
+	ready := buf.onConnected(id, nil)
+	for len(ready) != 0 {
+		child := ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+		_, ready = buf.tryEvent(child, true, ready)
+	}
+	buf.pushReinjected()
+}
+
+// indexDeps adds the incomplete event into the index of the missing parents
+func (buf *EventsBuffer) indexDeps(e *event, missing hash.Events) {
+	e.missing = 0
+	for _, p := range missing {
+		children := buf.deps[p]
+		if children == nil {
+			children = make(map[hash.Event]bool)
+			buf.deps[p] = children
+		}
+		if !children[e.event.ID()] {
+			children[e.event.ID()] = true
+			e.missing++
+			buf.updateChildren(p)
+		}
+	}
+}
+
+// unindexDeps removes the event from the index of the missing parents
+func (buf *EventsBuffer) unindexDeps(e *event) {
+	if e.missing == 0 {
+		return
+	}
+	for _, p := range e.event.Parents() {
+		children := buf.deps[p]
+		if children == nil {
+			continue
+		}
+		delete(children, e.event.ID())
+		if len(children) == 0 {
+			delete(buf.deps, p)
 		}
-		for _, child := range incompleteEventsList {
-			for _, parent := range child.event.Parents() {
-				if parent == eHash {
-					buf.pushEvent(child, incompleteEventsList, true)
-					break
-				}
-			}
+		buf.updateChildren(p)
+	}
+	e.missing = 0
+}
+
+// updateChildren updates the number of waiting children of the event in the eviction index, if the event is buffered
+func (buf *EventsBuffer) updateChildren(id hash.Event) {
+	if val, ok := buf.incompletes.Peek(id); ok {
+		buf.evictions.SetChildren(val.(*event), len(buf.deps[id]))
+	}
+}
+
+// removeIncomplete removes the event from the incomplete events
+func (buf *EventsBuffer) removeIncomplete(e *event) {
+	if buf.incompletes.Remove(e.event.ID()) {
+		buf.evictions.Remove(e)
+		buf.unindexDeps(e)
+	}
+}
+
+// takeOverflowed takes the events from the disk tier, which wait for the parent.
+// They are pushed after the current event, so that the disk tier isn't read recursively.
+func (buf *EventsBuffer) takeOverflowed(parent hash.Event) {
+	if buf.overflow == nil {
+		return
+	}
+	events, peers := buf.overflow.Take(parent)
+	for i, child := range events {
+		buf.reinjected = append(buf.reinjected, &event{
+			event:      child,
+			peer:       peers[i],
+			overflowed: true,
+		})
+	}
+}
+
+// pushReinjected pushes the events which are taken from the disk tier
+func (buf *EventsBuffer) pushReinjected() {
+	for len(buf.reinjected) != 0 {
+		e := buf.reinjected[0]
+		buf.reinjected = buf.reinjected[1:]
+		if buf.incompletes.Contains(e.event.ID()) {
+			buf.dropEvent(e, eventcheck.ErrDuplicateEvent)
+			buf.releaseEvent(e)
+			continue
 		}
+		buf.pushEvent(e, false)
 	}
-	buf.incompletes.Remove(e.event.ID())
-	return ok
+	buf.reinjected = nil
 }
 
 func (buf *EventsBuffer) getIncompleteEventsList() []*event {
@@ -121,16 +279,18 @@ func (buf *EventsBuffer) getIncompleteEventsList() []*event {
 	return res
 }
 
-func (buf *EventsBuffer) completeEventParents(e *event) dag.Events {
+// resolveEventParents returns the parents of the event, or the missing parents if some parents aren't connected
+func (buf *EventsBuffer) resolveEventParents(e *event) (dag.Events, hash.Events) {
 	parents := make(dag.Events, len(e.event.Parents()))
+	var missing hash.Events
 	for i, p := range e.event.Parents() {
 		parent := buf.callback.Get(p)
 		if parent == nil {
-			return nil
+			missing = append(missing, p)
 		}
 		parents[i] = parent
 	}
-	return parents
+	return parents, missing
 }
 
 func (buf *EventsBuffer) processCompleteEvent(e *event, parents dag.Events) bool {
@@ -153,18 +313,57 @@ func (buf *EventsBuffer) processCompleteEvent(e *event, parents dag.Events) bool
 	return true
 }
 
+// spillIncompletes evicts incomplete events until the limit is satisfied.
+// Events are evicted in the order of evictionScore, so events which are far from the highest Lamport time are dropped first,
+// and events with many waiting children are kept.
 func (buf *EventsBuffer) spillIncompletes(limit dag.Metric) {
-	for idx.Event(buf.incompletes.Len()) > limit.Num || uint64(buf.incompletes.Weight()) > limit.Size {
-		_, val, ok := buf.incompletes.RemoveOldest()
-		if !ok {
+	if !buf.overLimit(limit) {
+		return
+	}
+	var highestLamport idx.Lamport
+	if buf.callback.HighestLamport != nil {
+		highestLamport = buf.callback.HighestLamport()
+	}
+	for buf.overLimit(limit) {
+		e := buf.evictions.Worst(highestLamport)
+		if e == nil {
 			break
 		}
-		e := val.(*event)
-		buf.dropEvent(e, eventcheck.ErrSpilledEvent)
-		buf.releaseEvent(e)
+		buf.removeIncomplete(e)
+		buf.spillEvent(e)
 	}
 }
 
+func (buf *EventsBuffer) overLimit(limit dag.Metric) bool {
+	return idx.Event(buf.incompletes.Len()) > limit.Num || uint64(buf.incompletes.Weight()) > limit.Size
+}
+
+func (buf *EventsBuffer) spillEvent(e *event) {
+	if buf.overflowEvent(e) {
+		// the event is released when it leaves the disk tier. A re-injected event is reported once
+		if !e.overflowed && buf.callback.Overflowed != nil {
+			buf.callback.Overflowed(e.event, e.peer)
+		}
+		return
+	}
+	buf.dropEvent(e, eventcheck.ErrSpilledEvent)
+	buf.releaseEvent(e)
+}
+
+// overflowEvent stores the event in the disk tier, indexed by its missing parents
+func (buf *EventsBuffer) overflowEvent(e *event) bool {
+	if buf.overflow == nil {
+		return false
+	}
+	var missing hash.Events
+	for _, p := range e.event.Parents() {
+		if !buf.callback.Exists(p) {
+			missing = append(missing, p)
+		}
+	}
+	return buf.overflow.Add(e.event, e.peer, missing)
+}
+
 func (buf *EventsBuffer) dropEvent(e *event, err error) {
 	if e.err == nil {
 		e.err = err
@@ -172,21 +371,75 @@ func (buf *EventsBuffer) dropEvent(e *event, err error) {
 }
 
 func (buf *EventsBuffer) releaseEvent(e *event) {
-	if buf.callback.Released != nil && !e.released {
-		buf.callback.Released(e.event, e.peer, e.err)
+	if e.released {
+		return
 	}
 	e.released = true
+	if e.overflowed && buf.callback.ReleasedOverflowed != nil {
+		buf.callback.ReleasedOverflowed(e.event, e.peer, e.err)
+	} else if buf.callback.Released != nil {
+		buf.callback.Released(e.event, e.peer, e.err)
+	}
+}
+
+// releaseOverflowed releases the events which are erased from the disk tier
+func (buf *EventsBuffer) releaseOverflowed(events dag.Events, peers []string, err error) {
+	for i, e := range events {
+		buf.releaseEvent(&event{
+			event:      e,
+			peer:       peers[i],
+			err:        err,
+			overflowed: true,
+		})
+	}
 }
 
 func (buf *EventsBuffer) IsBuffered(id hash.Event) bool {
-	// wlru is thread-safe, no need for a mutex here
-	return buf.incompletes.Contains(id)
+	// wlru and Overflow are thread-safe, no need for a mutex here
+	if buf.incompletes.Contains(id) {
+		return true
+	}
+	return buf.overflow != nil && buf.overflow.Contains(id)
 }
 
 func (buf *EventsBuffer) Clear() {
 	buf.mu.Lock()
 	defer buf.mu.Unlock()
-	buf.spillIncompletes(dag.Metric{})
+	// don't move the events to the disk tier
+	for {
+		_, val, ok := buf.incompletes.RemoveOldest()
+		if !ok {
+			break
+		}
+		e := val.(*event)
+		buf.dropEvent(e, eventcheck.ErrDroppedEvent)
+		buf.releaseEvent(e)
+	}
+	buf.deps = make(map[hash.Event]map[hash.Event]bool)
+	buf.evictions = newEvictionIndex()
+	if buf.overflow != nil {
+		events, peers := buf.overflow.Clear()
+		buf.releaseOverflowed(events, peers, eventcheck.ErrDroppedEvent)
+	}
+}
+
+// OnEpochSealed erases the events of the disk tier, because they aren't of the current epoch anymore
+func (buf *EventsBuffer) OnEpochSealed(epoch idx.Epoch) {
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	if buf.overflow != nil {
+		events, peers := buf.overflow.Reset(epoch)
+		buf.releaseOverflowed(events, peers, eventcheck.ErrDroppedEvent)
+	}
+}
+
+// TotalOverflowed returns the total encoded size and number of events in the disk tier
+func (buf *EventsBuffer) TotalOverflowed() dag.Metric {
+	// Overflow is thread-safe, no need for a mutex here
+	if buf.overflow == nil {
+		return dag.Metric{}
+	}
+	return buf.overflow.Total()
 }
 
 // Total returns the total weight and number of items in the cache.
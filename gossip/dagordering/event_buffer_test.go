@@ -0,0 +1,205 @@
+package dagordering
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+func genBenchEvents(eventsNum int) dag.Events {
+	var ordered dag.Events
+	_ = tdag.ForEachRandEvent(tdag.GenNodes(100), eventsNum/100, 5, rand.New(rand.NewSource(0)), tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+		},
+		Build: func(e dag.MutableEvent, name string) error {
+			e.SetEpoch(1)
+			e.SetFrame(idx.Frame(e.Seq()))
+			return nil
+		},
+	})
+	return ordered
+}
+
+func benchmarkEventsBuffer(b *testing.B, events dag.Events) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		processed := make(map[hash.Event]dag.Event, len(events))
+		var spilled dag.Events
+		// the limit is below the input size, so that the eviction is measured too
+		buffer := New(dag.Metric{Num: idx.Event(len(events) / 10), Size: events.Metric().Size / 10}, Callback{
+			Process: func(e dag.Event) error {
+				processed[e.ID()] = e
+				return nil
+			},
+			Released: func(e dag.Event, peer string, err error) {
+				if err == eventcheck.ErrSpilledEvent {
+					spilled = append(spilled, e)
+				}
+			},
+			Exists: func(id hash.Event) bool {
+				return processed[id] != nil
+			},
+			Get: func(id hash.Event) dag.Event {
+				return processed[id]
+			},
+		})
+		// the spilled events are pushed again, as if they are re-fetched
+		for pending := events; len(pending) != 0; {
+			spilled = nil
+			for _, e := range pending {
+				buffer.PushEvent(e, "")
+			}
+			pending = spilled
+		}
+		if len(processed) != len(events) {
+			b.Fatal("not all the events are processed", len(processed), len(events))
+		}
+	}
+	b.ReportMetric(float64(len(events)*b.N)/b.Elapsed().Seconds(), "events/s")
+}
+
+// BenchmarkEventsBuffer pushes events in the reversed and in a random order,
+// so that most of the events are buffered before their parents arrive
+func BenchmarkEventsBuffer(b *testing.B) {
+	for _, eventsNum := range []int{5000, 50000} {
+		ordered := genBenchEvents(eventsNum)
+
+		reversed := make(dag.Events, len(ordered))
+		for i, e := range ordered {
+			reversed[len(ordered)-1-i] = e
+		}
+		b.Run(fmt.Sprintf("reversed/%d", eventsNum), func(b *testing.B) {
+			benchmarkEventsBuffer(b, reversed)
+		})
+
+		shuffled := make(dag.Events, len(ordered))
+		for i, j := range rand.New(rand.NewSource(1)).Perm(len(ordered)) {
+			shuffled[i] = ordered[j]
+		}
+		b.Run(fmt.Sprintf("shuffled/%d", eventsNum), func(b *testing.B) {
+			benchmarkEventsBuffer(b, shuffled)
+		})
+	}
+}
+
+// checkDeps checks that the reverse-dependency index and the eviction index are consistent with the incomplete events
+func checkDeps(t testing.TB, buf *EventsBuffer) {
+	waiting := make(map[hash.Event]int)
+	for p, children := range buf.deps {
+		if len(children) == 0 {
+			t.Fatal("empty deps of", p.String())
+		}
+		for child := range children {
+			if !buf.incompletes.Contains(child) {
+				t.Fatal("deps contain not buffered event", child.String())
+			}
+			waiting[child]++
+		}
+	}
+	incompletes := buf.getIncompleteEventsList()
+	for _, e := range incompletes {
+		if e.missing != waiting[e.event.ID()] {
+			t.Fatal("wrong number of missing parents", e.event.String(), e.missing, waiting[e.event.ID()])
+		}
+		g := buf.evictions.groups[e.children]
+		if e.children != len(buf.deps[e.event.ID()]) || g == nil ||
+			g.lowest.events[e.lowestPos] != e || g.highest.events[e.highestPos] != e {
+			t.Fatal("event isn't indexed for eviction", e.event.String())
+		}
+	}
+	if buf.evictions.Len() != len(incompletes) {
+		t.Fatal("wrong number of events in the eviction index", buf.evictions.Len(), len(incompletes))
+	}
+}
+
+func TestEventsBufferIndexConsistency(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		t.Run("memory", func(t *testing.T) {
+			testEventsBufferIndexConsistency(t, seed, false)
+		})
+		t.Run("overflow", func(t *testing.T) {
+			testEventsBufferIndexConsistency(t, seed, true)
+		})
+	}
+}
+
+// testEventsBufferIndexConsistency checks the indexes of the buffer after each push,
+// while the events are completed, evicted, moved into the disk tier and re-injected
+func testEventsBufferIndexConsistency(t *testing.T, seed int64, overflow bool) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(seed))
+	var ordered dag.Events
+	_ = tdag.ForEachRandEvent(tdag.GenNodes(5), 30, 3, r, tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+		},
+		Build: func(e dag.MutableEvent, name string) error {
+			e.SetEpoch(1)
+			e.SetFrame(idx.Frame(e.Seq()))
+			return nil
+		},
+	})
+
+	var highestLamport idx.Lamport
+	processed := make(map[hash.Event]dag.Event)
+	var spilled dag.Events
+	buffer := New(dag.Metric{Num: 5, Size: 1 << 20}, Callback{
+		Process: func(e dag.Event) error {
+			processed[e.ID()] = e
+			if highestLamport < e.Lamport() {
+				highestLamport = e.Lamport()
+			}
+			return nil
+		},
+		Released: func(e dag.Event, peer string, err error) {
+			if err == eventcheck.ErrSpilledEvent {
+				spilled = append(spilled, e)
+			}
+		},
+		Exists: func(id hash.Event) bool {
+			return processed[id] != nil
+		},
+		Get: func(id hash.Event) dag.Event {
+			return processed[id]
+		},
+		HighestLamport: func() idx.Lamport {
+			return highestLamport
+		},
+	})
+	if overflow {
+		// the disk tier is small too, so that some events are dropped
+		buffer.SetOverflow(newTestOverflow(dag.Metric{Num: 10, Size: 1 << 20}))
+	}
+
+	// the spilled events are pushed again, as if they are re-fetched
+	pending := make(dag.Events, len(ordered))
+	for i, j := range r.Perm(len(ordered)) {
+		pending[i] = ordered[j]
+	}
+	for len(pending) != 0 {
+		spilled = nil
+		for _, e := range pending {
+			buffer.PushEvent(e, "")
+			checkDeps(t, buffer)
+		}
+		pending = spilled
+	}
+
+	for _, e := range ordered {
+		require.Contains(processed, e.ID())
+	}
+	require.Equal(dag.Metric{}, buffer.Total())
+	require.Equal(dag.Metric{}, buffer.TotalOverflowed())
+	require.Empty(buffer.deps)
+	require.Zero(buffer.evictions.Len())
+}
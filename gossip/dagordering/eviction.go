@@ -0,0 +1,143 @@
+package dagordering
+
+import (
+	"container/heap"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+// evictionIndex orders the incomplete events for eviction by evictionScore.
+// The score depends on the highest Lamport time, which changes over time, so the events cannot be ordered by the score.
+// Instead, the events are grouped by the number of waiting children, and each group is ordered by Lamport time.
+// The Lamport distance is the largest either for the lowest or for the highest event of a group,
+// so the worst event is one of the two boundary events of a group.
+type evictionIndex struct {
+	groups map[int]*evictionGroup
+}
+
+type evictionGroup struct {
+	lowest  lamportHeap
+	highest lamportHeap
+}
+
+func newEvictionIndex() *evictionIndex {
+	return &evictionIndex{
+		groups: make(map[int]*evictionGroup),
+	}
+}
+
+// Add indexes the event with the number of its waiting children
+func (ei *evictionIndex) Add(e *event, children int) {
+	e.children = children
+	g := ei.groups[children]
+	if g == nil {
+		g = &evictionGroup{
+			highest: lamportHeap{max: true},
+		}
+		ei.groups[children] = g
+	}
+	heap.Push(&g.lowest, e)
+	heap.Push(&g.highest, e)
+}
+
+// Remove unindexes the event
+func (ei *evictionIndex) Remove(e *event) {
+	g := ei.groups[e.children]
+	heap.Remove(&g.lowest, e.lowestPos)
+	heap.Remove(&g.highest, e.highestPos)
+	if g.lowest.Len() == 0 {
+		delete(ei.groups, e.children)
+	}
+}
+
+// SetChildren moves the event into the group of the new number of waiting children
+func (ei *evictionIndex) SetChildren(e *event, children int) {
+	if e.children == children {
+		return
+	}
+	ei.Remove(e)
+	ei.Add(e, children)
+}
+
+// Worst returns the event with the highest evictionScore, or nil if the index is empty.
+// Of the events with equal score, the event with less children is returned, and then the event with higher Lamport time
+func (ei *evictionIndex) Worst(highestLamport idx.Lamport) *event {
+	var worst *event
+	var worstScore float64
+	for children, g := range ei.groups {
+		for _, e := range [2]*event{g.highest.events[0], g.lowest.events[0]} {
+			score := evictionScore(e.event, highestLamport, children)
+			if worst == nil || score > worstScore || score == worstScore && children < worst.children {
+				worst, worstScore = e, score
+			}
+		}
+	}
+	return worst
+}
+
+// Len returns the number of the indexed events
+func (ei *evictionIndex) Len() int {
+	n := 0
+	for _, g := range ei.groups {
+		n += g.lowest.Len()
+	}
+	return n
+}
+
+// evictionScore is the Lamport distance of the event from the highest Lamport time,
+// divided by the number of waiting children plus one.
+// Events far below the highest Lamport time are unlikely to be completed, as well as the events far above it
+func evictionScore(e dag.Event, highestLamport idx.Lamport, children int) float64 {
+	var distance idx.Lamport
+	if e.Lamport() > highestLamport {
+		distance = e.Lamport() - highestLamport
+	} else {
+		distance = highestLamport - e.Lamport()
+	}
+	return float64(distance) / float64(1+children)
+}
+
+// lamportHeap is a heap of events ordered by Lamport time, which maintains the positions of the events
+type lamportHeap struct {
+	events []*event
+	max    bool
+}
+
+func (h lamportHeap) Len() int {
+	return len(h.events)
+}
+
+func (h lamportHeap) Less(i, j int) bool {
+	if h.max {
+		return h.events[i].event.Lamport() > h.events[j].event.Lamport()
+	}
+	return h.events[i].event.Lamport() < h.events[j].event.Lamport()
+}
+
+func (h lamportHeap) Swap(i, j int) {
+	h.events[i], h.events[j] = h.events[j], h.events[i]
+	h.setPos(i)
+	h.setPos(j)
+}
+
+func (h lamportHeap) setPos(i int) {
+	if h.max {
+		h.events[i].highestPos = i
+	} else {
+		h.events[i].lowestPos = i
+	}
+}
+
+func (h *lamportHeap) Push(x interface{}) {
+	h.events = append(h.events, x.(*event))
+	h.setPos(len(h.events) - 1)
+}
+
+func (h *lamportHeap) Pop() interface{} {
+	n := len(h.events)
+	e := h.events[n-1]
+	h.events[n-1] = nil
+	h.events = h.events[:n-1]
+	return e
+}
@@ -0,0 +1,210 @@
+package dagordering
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+// floodEvent creates an event of the attacker, which has the parents or an unknown parent
+func floodEvent(r *rand.Rand, lamport idx.Lamport, parents ...hash.Event) *tdag.TestEvent {
+	e := &tdag.TestEvent{}
+	e.SetEpoch(1)
+	e.SetCreator(1000)
+	e.SetSeq(2)
+	e.SetFrame(1)
+	e.SetLamport(lamport)
+	if len(parents) == 0 {
+		var unknown hash.Event
+		r.Read(unknown[:])
+		parents = append(parents, unknown)
+	}
+	e.SetParents(parents)
+	var id [24]byte
+	r.Read(id[:])
+	e.SetID(id)
+	return e
+}
+
+type floodFn func(r *rand.Rand, flood dag.Events) dag.Event
+
+// testEventsBufferFlood pushes honest events in a random order, interleaved with the flood of the attacker.
+// Incomplete honest events fit into the limit, so none of them must be spilled.
+func testEventsBufferFlood(t *testing.T, seed int64, floodPerEvent int, gen floodFn) {
+	r := rand.New(rand.NewSource(seed))
+	var ordered dag.Events
+	_ = tdag.ForEachRandEvent(tdag.GenNodes(5), 20, 3, r, tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+		},
+		Build: func(e dag.MutableEvent, name string) error {
+			e.SetEpoch(1)
+			e.SetFrame(idx.Frame(e.Seq()))
+			return nil
+		},
+	})
+	honest := ordered.IDs().Set()
+
+	var highestLamport idx.Lamport
+	processed := make(map[hash.Event]dag.Event)
+	spilledFlood := 0
+	buffer := New(dag.Metric{Num: idx.Event(len(ordered)) + 10, Size: 1 << 30}, Callback{
+		Process: func(e dag.Event) error {
+			processed[e.ID()] = e
+			if highestLamport < e.Lamport() {
+				highestLamport = e.Lamport()
+			}
+			return nil
+		},
+		Released: func(e dag.Event, peer string, err error) {
+			if err == nil {
+				return
+			}
+			if honest.Contains(e.ID()) {
+				t.Fatalf("honest event %s is dropped with '%s'", e.String(), err)
+			}
+			spilledFlood++
+		},
+		Exists: func(id hash.Event) bool {
+			return processed[id] != nil
+		},
+		Get: func(id hash.Event) dag.Event {
+			return processed[id]
+		},
+		HighestLamport: func() idx.Lamport {
+			return highestLamport
+		},
+	})
+
+	var flood dag.Events
+	for _, i := range r.Perm(len(ordered)) {
+		buffer.PushEvent(ordered[i], "honest")
+		for j := 0; j < floodPerEvent; j++ {
+			e := gen(r, flood)
+			flood = append(flood, e)
+			buffer.PushEvent(e, "attacker")
+		}
+	}
+
+	for _, e := range ordered {
+		require.Contains(t, processed, e.ID())
+	}
+	require.Equal(t, len(flood)-int(buffer.Total().Num), spilledFlood)
+	checkDeps(t, buffer)
+}
+
+func TestEventsBufferFlood(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		t.Run("unknown parents", func(t *testing.T) {
+			// independent far future events
+			testEventsBufferFlood(t, seed, 10, func(r *rand.Rand, flood dag.Events) dag.Event {
+				return floodEvent(r, idx.Lamport(1000+r.Intn(1000000)))
+			})
+		})
+		t.Run("waiting children", func(t *testing.T) {
+			// far future events which refer to each other, so that they have many waiting children
+			testEventsBufferFlood(t, seed, 10, func(r *rand.Rand, flood dag.Events) dag.Event {
+				if len(flood) < 10 {
+					return floodEvent(r, 1000)
+				}
+				parents := hash.Events{}
+				for len(parents) < 3 {
+					parents.Add(flood[r.Intn(10)].ID())
+				}
+				return floodEvent(r, 1001, parents...)
+			})
+		})
+		t.Run("near future", func(t *testing.T) {
+			// events which are slightly above the honest events
+			testEventsBufferFlood(t, seed, 3, func(r *rand.Rand, flood dag.Events) dag.Event {
+				return floodEvent(r, idx.Lamport(100+r.Intn(100)))
+			})
+		})
+	}
+}
+
+func TestEventsBufferEvictionOrder(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	var spilled []hash.Event
+	buffer := New(dag.Metric{Num: 6, Size: 1 << 30}, Callback{
+		Released: func(e dag.Event, peer string, err error) {
+			spilled = append(spilled, e.ID())
+		},
+		Exists: func(id hash.Event) bool {
+			return false
+		},
+		Get: func(id hash.Event) dag.Event {
+			return nil
+		},
+		HighestLamport: func() idx.Lamport {
+			return 10
+		},
+	})
+	withChildren := floodEvent(r, 20)
+	leaf := floodEvent(r, 25)
+	near := floodEvent(r, 11)
+	far := floodEvent(r, 100)
+	buffer.PushEvent(withChildren, "")
+	buffer.PushEvent(leaf, "")
+	buffer.PushEvent(near, "")
+	for i := 0; i < 3; i++ {
+		buffer.PushEvent(floodEvent(r, 21, withChildren.ID()), "")
+	}
+	require.Empty(t, spilled)
+
+	buffer.PushEvent(far, "")
+	require.Equal(t, []hash.Event{far.ID()}, spilled)
+	buffer.PushEvent(floodEvent(r, 15), "")
+	require.Equal(t, []hash.Event{far.ID(), leaf.ID()}, spilled)
+	require.True(t, buffer.IsBuffered(withChildren.ID()))
+	require.True(t, buffer.IsBuffered(near.ID()))
+	checkDeps(t, buffer)
+}
+
+// TestEventsBufferEvictionBelowHighest checks that the events below the highest Lamport time are evicted
+// by the distance and the waiting children too, even if they are more recent than the kept events
+func TestEventsBufferEvictionBelowHighest(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	var spilled []hash.Event
+	buffer := New(dag.Metric{Num: 3, Size: 1 << 30}, Callback{
+		Released: func(e dag.Event, peer string, err error) {
+			spilled = append(spilled, e.ID())
+		},
+		Exists: func(id hash.Event) bool {
+			return false
+		},
+		Get: func(id hash.Event) dag.Event {
+			return nil
+		},
+		HighestLamport: func() idx.Lamport {
+			return 100
+		},
+	})
+	withChildren := floodEvent(r, 90)
+	leaf := floodEvent(r, 94)
+	child := floodEvent(r, 101, withChildren.ID())
+	near := floodEvent(r, 100)
+	stale := floodEvent(r, 10)
+	buffer.PushEvent(withChildren, "")
+	buffer.PushEvent(leaf, "")
+	buffer.PushEvent(child, "")
+	require.Empty(t, spilled)
+
+	// the oldest event is kept, because it has a waiting child
+	buffer.PushEvent(near, "")
+	require.Equal(t, []hash.Event{leaf.ID()}, spilled)
+
+	// the most recent event is evicted, because it's far below the highest Lamport time
+	buffer.PushEvent(stale, "")
+	require.Equal(t, []hash.Event{leaf.ID(), stale.ID()}, spilled)
+	require.True(t, buffer.IsBuffered(withChildren.ID()))
+	require.True(t, buffer.IsBuffered(child.ID()))
+	require.True(t, buffer.IsBuffered(near.ID()))
+	checkDeps(t, buffer)
+}
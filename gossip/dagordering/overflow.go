@@ -0,0 +1,268 @@
+package dagordering
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+)
+
+var (
+	// eventsPrefix is the prefix of event ID -> peer and encoded event
+	eventsPrefix = []byte("e")
+	// depsPrefix is the prefix of missing parent ID + event ID -> nil
+	depsPrefix = []byte("d")
+)
+
+// OverflowConfig is the disk quota of Overflow
+type OverflowConfig struct {
+	// Limit is the maximum number of events and their total encoded size
+	Limit dag.Metric
+}
+
+// DefaultOverflowConfig for livenet.
+func DefaultOverflowConfig() OverflowConfig {
+	return OverflowConfig{
+		Limit: dag.Metric{
+			Num:  200000,
+			Size: 512 * opt.MiB,
+		},
+	}
+}
+
+// OverflowCallback is a set of Overflow's args, which encode and decode events
+type OverflowCallback struct {
+	Marshal   func(dag.Event) ([]byte, error)
+	Unmarshal func([]byte) (dag.Event, error)
+}
+
+type overflowedEvent struct {
+	Peer  string
+	Event []byte
+}
+
+// Overflow is a disk tier of EventsBuffer. It stores the spilled incomplete events of the current epoch,
+// which are indexed by their missing parents, so that they are re-injected when the parents are connected.
+type Overflow struct {
+	cfg      OverflowConfig
+	callback OverflowCallback
+	crit     func(error)
+
+	db kvdb.Store
+
+	mu    sync.Mutex
+	epoch idx.Epoch
+	total dag.Metric
+}
+
+// NewOverflow creates Overflow instance. The previous content of db is erased.
+func NewOverflow(db kvdb.Store, cfg OverflowConfig, callback OverflowCallback, crit func(error)) *Overflow {
+	o := &Overflow{
+		cfg:      cfg,
+		callback: callback,
+		crit:     crit,
+		db:       db,
+	}
+	o.erase()
+	return o
+}
+
+// Add stores the event, which waits for the missing parents.
+// Returns false if the event isn't stored because the quota is exceeded, or the event isn't of the current epoch.
+// Events of a newer epoch aren't stored until the events of the current epoch are erased by Reset.
+func (o *Overflow) Add(e dag.Event, peer string, missing hash.Events) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if e.Epoch() < o.epoch || len(missing) == 0 {
+		return false
+	}
+	if e.Epoch() > o.epoch {
+		if o.total.Num != 0 {
+			return false
+		}
+		o.epoch = e.Epoch()
+	}
+	if o.has(e.ID()) {
+		return true
+	}
+	raw, err := o.callback.Marshal(e)
+	if err != nil {
+		return false
+	}
+	val, err := rlp.EncodeToBytes(&overflowedEvent{peer, raw})
+	if err != nil {
+		o.crit(err)
+	}
+	if o.total.Num+1 > o.cfg.Limit.Num || o.total.Size+uint64(len(val)) > o.cfg.Limit.Size {
+		return false
+	}
+
+	batch := o.db.NewBatch()
+	o.put(batch, eventsPrefix, e.ID().Bytes(), val)
+	for _, p := range missing {
+		o.put(batch, depsPrefix, append(p.Bytes(), e.ID().Bytes()...), []byte{})
+	}
+	if err := batch.Write(); err != nil {
+		o.crit(err)
+	}
+	o.total.Num++
+	o.total.Size += uint64(len(val))
+	return true
+}
+
+// Take removes and returns the events, which wait for the parent
+func (o *Overflow) Take(parent hash.Event) (events dag.Events, peers []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var children hash.Events
+	prefix := key(depsPrefix, parent.Bytes())
+	it := o.db.NewIterator(prefix, nil)
+	for it.Next() {
+		children = append(children, hash.BytesToEvent(it.Key()[len(prefix):]))
+	}
+	if err := it.Error(); err != nil {
+		o.crit(err)
+	}
+	it.Release()
+	if len(children) == 0 {
+		return nil, nil
+	}
+
+	batch := o.db.NewBatch()
+	for _, child := range children {
+		val, err := o.db.Get(key(eventsPrefix, child.Bytes()))
+		if err != nil {
+			o.crit(err)
+		}
+		o.delete(batch, depsPrefix, append(parent.Bytes(), child.Bytes()...))
+		if val == nil {
+			continue
+		}
+		e, peer := o.decode(val)
+		o.delete(batch, eventsPrefix, child.Bytes())
+		for _, p := range e.Parents() {
+			o.delete(batch, depsPrefix, append(p.Bytes(), child.Bytes()...))
+		}
+		o.total.Num--
+		o.total.Size -= uint64(len(val))
+		events = append(events, e)
+		peers = append(peers, peer)
+	}
+	if err := batch.Write(); err != nil {
+		o.crit(err)
+	}
+	return events, peers
+}
+
+func (o *Overflow) decode(val []byte) (dag.Event, string) {
+	var oe overflowedEvent
+	if err := rlp.DecodeBytes(val, &oe); err != nil {
+		o.crit(err)
+	}
+	e, err := o.callback.Unmarshal(oe.Event)
+	if err != nil {
+		o.crit(err)
+	}
+	return e, oe.Peer
+}
+
+// Contains returns true if the event is stored
+func (o *Overflow) Contains(id hash.Event) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.has(id)
+}
+
+// Reset erases all the events and sets the current epoch. The erased events are returned, so that they may be released
+func (o *Overflow) Reset(epoch idx.Epoch) (events dag.Events, peers []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	events, peers = o.erase()
+	o.epoch = epoch
+	return events, peers
+}
+
+// Clear erases all the events. The erased events are returned, so that they may be released
+func (o *Overflow) Clear() (events dag.Events, peers []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.erase()
+}
+
+// Total returns the number of stored events and their total encoded size
+func (o *Overflow) Total() dag.Metric {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.total
+}
+
+// erase deletes all the events, and returns the events which are stored since the epoch is set
+func (o *Overflow) erase() (events dag.Events, peers []string) {
+	if o.total.Num == 0 && o.epoch != 0 {
+		return nil, nil
+	}
+	for _, prefix := range [][]byte{eventsPrefix, depsPrefix} {
+		batch := o.db.NewBatch()
+		it := o.db.NewIterator(prefix, nil)
+		for it.Next() {
+			// events of a previous run are erased on creation, when the epoch isn't set yet
+			if o.epoch != 0 && bytes.Equal(prefix, eventsPrefix) {
+				e, peer := o.decode(it.Value())
+				events = append(events, e)
+				peers = append(peers, peer)
+			}
+			if err := batch.Delete(it.Key()); err != nil {
+				o.crit(err)
+			}
+		}
+		if err := it.Error(); err != nil {
+			o.crit(err)
+		}
+		it.Release()
+		if err := batch.Write(); err != nil {
+			o.crit(err)
+		}
+	}
+	o.total = dag.Metric{}
+	return events, peers
+}
+
+func (o *Overflow) has(id hash.Event) bool {
+	ok, err := o.db.Has(key(eventsPrefix, id.Bytes()))
+	if err != nil {
+		o.crit(err)
+	}
+	return ok
+}
+
+func key(prefix []byte, parts ...[]byte) []byte {
+	res := append([]byte{}, prefix...)
+	for _, part := range parts {
+		res = append(res, part...)
+	}
+	return res
+}
+
+func (o *Overflow) put(batch kvdb.Batch, prefix []byte, k, val []byte) {
+	if err := batch.Put(key(prefix, k), val); err != nil {
+		o.crit(err)
+	}
+}
+
+func (o *Overflow) delete(batch kvdb.Batch, prefix []byte, k []byte) {
+	if err := batch.Delete(key(prefix, k)); err != nil {
+		o.crit(err)
+	}
+}
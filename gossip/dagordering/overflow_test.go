@@ -0,0 +1,279 @@
+package dagordering
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+)
+
+func testOverflowCallback() OverflowCallback {
+	return OverflowCallback{
+		Marshal: func(e dag.Event) ([]byte, error) {
+			return e.(*tdag.TestEvent).Bytes(), nil
+		},
+		Unmarshal: func(b []byte) (dag.Event, error) {
+			var m tdag.TestEventMarshaling
+			if err := rlp.DecodeBytes(b, &m); err != nil {
+				return nil, err
+			}
+			e := &tdag.TestEvent{Name: m.Name}
+			e.SetEpoch(m.Epoch)
+			e.SetSeq(m.Seq)
+			e.SetFrame(m.Frame)
+			e.SetCreator(m.Creator)
+			e.SetParents(m.Parents)
+			e.SetLamport(m.Lamport)
+			var id [24]byte
+			copy(id[:], m.ID.Bytes()[8:])
+			e.SetID(id)
+			return e, nil
+		},
+	}
+}
+
+func newTestOverflow(limit dag.Metric) *Overflow {
+	return NewOverflow(memorydb.New(), OverflowConfig{limit}, testOverflowCallback(), func(err error) {
+		panic(err)
+	})
+}
+
+func TestOverflow(t *testing.T) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(0))
+	a := floodEvent(r, 2)
+	b := floodEvent(r, 2)
+	c := floodEvent(r, 3, a.ID(), b.ID())
+	d := floodEvent(r, 3, a.ID())
+
+	o := newTestOverflow(dag.Metric{Num: 3, Size: 1 << 20})
+	require.False(o.Add(c, "peer", nil))
+	require.True(o.Add(c, "peer", hash.Events{a.ID(), b.ID()}))
+	require.True(o.Add(c, "peer", hash.Events{a.ID(), b.ID()}))
+	require.True(o.Add(d, "peer", hash.Events{a.ID()}))
+	require.True(o.Contains(c.ID()))
+	require.Equal(idx.Event(2), o.Total().Num)
+
+	// an event is taken by any of the missing parents
+	events, peers := o.Take(b.ID())
+	require.Equal(dag.Events{c}, events)
+	require.Equal([]string{"peer"}, peers)
+	require.False(o.Contains(c.ID()))
+	events, _ = o.Take(a.ID())
+	require.Equal(dag.Events{d}, events)
+	events, _ = o.Take(a.ID())
+	require.Empty(events)
+	require.Equal(dag.Metric{}, o.Total())
+
+	// quota
+	for i := 0; i < 3; i++ {
+		require.True(o.Add(floodEvent(r, 3, a.ID()), "", hash.Events{a.ID()}))
+	}
+	require.False(o.Add(floodEvent(r, 3, a.ID()), "", hash.Events{a.ID()}))
+	small := newTestOverflow(dag.Metric{Num: 3, Size: 10})
+	require.False(small.Add(c, "", hash.Events{a.ID()}))
+
+	// epoch change, events of the next epoch aren't stored until the erased events are released
+	next := floodEvent(r, 3, a.ID())
+	next.SetEpoch(2)
+	require.False(o.Add(next, "", hash.Events{a.ID()}))
+	events, _ = o.Reset(2)
+	require.Len(events, 3)
+	require.True(o.Add(next, "peer", hash.Events{a.ID()}))
+	require.Equal(idx.Event(1), o.Total().Num)
+	require.False(o.Add(floodEvent(r, 3, a.ID()), "", hash.Events{a.ID()}))
+	events, peers = o.Reset(3)
+	require.Len(events, 1)
+	require.Equal([]string{"peer"}, peers)
+	require.Equal(dag.Metric{}, o.Total())
+	require.False(o.Contains(next.ID()))
+
+	c.SetEpoch(3)
+	require.True(o.Add(c, "", hash.Events{a.ID()}))
+	events, _ = o.Clear()
+	require.Len(events, 1)
+	require.False(o.Contains(c.ID()))
+	events, _ = o.Take(a.ID())
+	require.Empty(events)
+}
+
+func TestEventsBufferOverflow(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		testEventsBufferOverflow(t, seed)
+	}
+}
+
+// testEventsBufferOverflow checks that all the events are connected if the memory limit is tiny
+func testEventsBufferOverflow(t *testing.T, seed int64) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(seed))
+	var ordered dag.Events
+	_ = tdag.ForEachRandEvent(tdag.GenNodes(5), 30, 3, r, tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+		},
+		Build: func(e dag.MutableEvent, name string) error {
+			e.SetEpoch(1)
+			e.SetFrame(idx.Frame(e.Seq()))
+			return nil
+		},
+	})
+
+	processed := make(map[hash.Event]dag.Event)
+	released := make(map[hash.Event]int)
+	overflowed := make(map[hash.Event]int)
+	buffer := New(dag.Metric{Num: 3, Size: 1 << 20}, Callback{
+		Process: func(e dag.Event) error {
+			for _, p := range e.Parents() {
+				require.Contains(processed, p)
+			}
+			processed[e.ID()] = e
+			return nil
+		},
+		Released: func(e dag.Event, peer string, err error) {
+			require.NoError(err)
+			require.Contains(processed, e.ID())
+			released[e.ID()]++
+		},
+		Overflowed: func(e dag.Event, peer string) {
+			overflowed[e.ID()]++
+			require.Equal(1, overflowed[e.ID()], "event is moved into the disk tier from the memory twice")
+		},
+		// the overflowed events are released only after they leave the disk tier
+		ReleasedOverflowed: func(e dag.Event, peer string, err error) {
+			require.NoError(err)
+			require.Contains(processed, e.ID())
+			released[e.ID()]++
+		},
+		Exists: func(id hash.Event) bool {
+			return processed[id] != nil
+		},
+		Get: func(id hash.Event) dag.Event {
+			return processed[id]
+		},
+	})
+	buffer.SetOverflow(newTestOverflow(dag.Metric{Num: 1000, Size: 1 << 20}))
+
+	for _, i := range r.Perm(len(ordered)) {
+		buffer.PushEvent(ordered[i], "")
+		require.LessOrEqual(buffer.Total().Num, idx.Event(3))
+	}
+	for _, e := range ordered {
+		require.Contains(processed, e.ID())
+		require.Equal(1, released[e.ID()])
+		require.False(buffer.IsBuffered(e.ID()))
+	}
+	require.NotEmpty(overflowed)
+	require.Equal(dag.Metric{}, buffer.TotalOverflowed())
+	require.Empty(buffer.deps)
+}
+
+// TestEventsBufferConnectedOutside checks that the buffered events are processed
+// when their parent is connected not by the buffer
+func TestEventsBufferConnectedOutside(t *testing.T) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(0))
+	parent := floodEvent(r, 1)
+	inMemory := floodEvent(r, 2, parent.ID())
+	onDisk := floodEvent(r, 2, parent.ID())
+	lateParent := floodEvent(r, 1)
+	lateChild := floodEvent(r, 2, lateParent.ID())
+
+	processed := make(map[hash.Event]dag.Event)
+	released := make(map[hash.Event]error)
+	buffer := New(dag.Metric{Num: 1, Size: 1 << 20}, Callback{
+		Process: func(e dag.Event) error {
+			processed[e.ID()] = e
+			return nil
+		},
+		Released: func(e dag.Event, peer string, err error) {
+			released[e.ID()] = err
+		},
+		Exists: func(id hash.Event) bool {
+			return processed[id] != nil
+		},
+		Get: func(id hash.Event) dag.Event {
+			return processed[id]
+		},
+	})
+	buffer.SetOverflow(newTestOverflow(dag.Metric{Num: 1000, Size: 1 << 20}))
+
+	buffer.PushEvent(onDisk, "")
+	buffer.PushEvent(inMemory, "")
+	require.Equal(idx.Event(1), buffer.TotalOverflowed().Num)
+	require.Empty(released)
+
+	// the parent is connected outside and the buffer is notified
+	processed[parent.ID()] = parent
+	buffer.OnConnected(parent.ID())
+	require.Contains(processed, inMemory.ID())
+	require.Contains(processed, onDisk.ID())
+	require.Equal(dag.Metric{}, buffer.TotalOverflowed())
+
+	// the parent is connected outside and then pushed into the buffer
+	buffer.PushEvent(lateChild, "")
+	processed[lateParent.ID()] = lateParent
+	buffer.PushEvent(lateParent, "")
+	require.Contains(processed, lateChild.ID())
+	require.Equal(eventcheck.ErrAlreadyConnectedEvent, released[lateParent.ID()])
+
+	for _, e := range []dag.Event{inMemory, onDisk, lateChild} {
+		require.Contains(released, e.ID())
+		require.NoError(released[e.ID()])
+	}
+	require.Equal(idx.Event(0), buffer.Total().Num)
+	checkDeps(t, buffer)
+}
+
+// TestEventsBufferOverflowErased checks that the events which are erased from the disk tier are released
+func TestEventsBufferOverflowErased(t *testing.T) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(0))
+	var events dag.Events
+	for i := 0; i < 3; i++ {
+		events = append(events, floodEvent(r, 2))
+	}
+
+	released := make(map[hash.Event]error)
+	buffer := New(dag.Metric{Num: 1, Size: 1 << 20}, Callback{
+		Released: func(e dag.Event, peer string, err error) {
+			t.Fatal("event is released as in-memory", e.String())
+		},
+		ReleasedOverflowed: func(e dag.Event, peer string, err error) {
+			require.NotContains(released, e.ID())
+			released[e.ID()] = err
+		},
+		Exists: func(id hash.Event) bool {
+			return false
+		},
+		Get: func(id hash.Event) dag.Event {
+			return nil
+		},
+	})
+	buffer.SetOverflow(newTestOverflow(dag.Metric{Num: 1000, Size: 1 << 20}))
+
+	for _, e := range events {
+		buffer.PushEvent(e, "")
+	}
+	require.Empty(released)
+	require.Equal(idx.Event(2), buffer.TotalOverflowed().Num)
+
+	buffer.OnEpochSealed(2)
+	require.Len(released, 2)
+	for _, err := range released {
+		require.Equal(eventcheck.ErrDroppedEvent, err)
+	}
+	require.Equal(dag.Metric{}, buffer.TotalOverflowed())
+}
@@ -12,6 +12,10 @@ import (
 type Config struct {
 	EventsBufferLimit dag.Metric
 
+	// QuarantineLimit is the limit of the events of the next epoch, which are stored until the current epoch is sealed.
+	// Zero limit disables the quarantine
+	QuarantineLimit dag.Metric
+
 	EventsSemaphoreTimeout time.Duration
 
 	MaxTasks int
@@ -20,10 +24,14 @@ type Config struct {
 func DefaultConfig(scale cachescale.Func) Config {
 	return Config{
 		EventsBufferLimit: dag.Metric{
-			// Shouldn't be too big because complexity is O(n) for each insertion in the EventsBuffer
+			// Shouldn't be too big, because the incomplete events are kept in memory
 			Num:  3000,
 			Size: scale.U64(10 * opt.MiB),
 		},
+		QuarantineLimit: dag.Metric{
+			Num:  1000,
+			Size: scale.U64(2 * opt.MiB),
+		},
 		EventsSemaphoreTimeout: 10 * time.Second,
 		MaxTasks:               128,
 	}
@@ -5,6 +5,8 @@ import (
 	"sync"
 
 	"github.com/Fantom-foundation/lachesis-base/eventcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/epochcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/forkcheck"
 	"github.com/Fantom-foundation/lachesis-base/gossip/dagordering"
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
@@ -14,22 +16,28 @@ import (
 )
 
 var (
-	ErrBusy = errors.New("failed to acquire events semaphore")
+	ErrBusy       = errors.New("failed to acquire events semaphore")
+	ErrPeerBanned = errors.New("peer is banned")
 )
 
 // Processor is responsible for processing incoming events
 type Processor struct {
 	cfg Config
 
-	quit chan struct{}
-	wg   sync.WaitGroup
+	quit    chan struct{}
+	wg      sync.WaitGroup
+	quitMu  sync.Mutex
+	stopped bool
 
 	callback Callback
+	released func(e dag.Event, peer string, err error)
 
 	checker         *workers.Workers
 	orderedInserter *workers.Workers
 
-	buffer *dagordering.EventsBuffer
+	buffer     *dagordering.EventsBuffer
+	quarantine *quarantine
+	forkcheck  *forkcheck.Checker
 
 	eventsSemaphore *datasemaphore.DataSemaphore
 }
@@ -46,6 +54,12 @@ type EventCallback struct {
 type Callback struct {
 	Event          EventCallback
 	HighestLamport func() idx.Lamport
+	// Epoch returns the current epoch. It's optional, events of the next epoch aren't quarantined if it's nil
+	Epoch func() idx.Epoch
+	// PeerBanned and PeerThrottled are optional, events of banned peers are rejected,
+	// and events of throttled peers are rejected if the processor is overloaded
+	PeerBanned    func(peer string) bool
+	PeerThrottled func(peer string) bool
 }
 
 // New creates an event processor
@@ -56,6 +70,7 @@ func New(eventsSemaphore *datasemaphore.DataSemaphore, cfg Config, callback Call
 		eventsSemaphore: eventsSemaphore,
 	}
 	released := callback.Event.Released
+	f.released = released
 	callback.Event.Released = func(e dag.Event, peer string, err error) {
 		f.eventsSemaphore.Release(dag.Metric{1, uint64(e.Size())})
 		if released != nil {
@@ -64,12 +79,25 @@ func New(eventsSemaphore *datasemaphore.DataSemaphore, cfg Config, callback Call
 	}
 	f.callback = callback
 	f.buffer = dagordering.New(cfg.EventsBufferLimit, dagordering.Callback{
-		Process:  callback.Event.Process,
+		Process:  f.processEvent,
 		Released: callback.Event.Released,
 		Get:      callback.Event.Get,
 		Exists:   callback.Event.Exists,
 		Check:    callback.Event.CheckParents,
+
+		HighestLamport: callback.HighestLamport,
+
+		// the disk tier has its own limit, the event doesn't hold the semaphore after it's moved there
+		Overflowed: func(e dag.Event, peer string) {
+			f.eventsSemaphore.Release(dag.Metric{Num: 1, Size: uint64(e.Size())})
+		},
+		ReleasedOverflowed: func(e dag.Event, peer string, err error) {
+			if released != nil {
+				released(e, peer, err)
+			}
+		},
 	})
+	f.quarantine = newQuarantine(cfg.QuarantineLimit)
 	f.orderedInserter = workers.New(&f.wg, f.quit, cfg.MaxTasks)
 	f.checker = workers.New(&f.wg, f.quit, cfg.MaxTasks)
 	return f
@@ -84,10 +112,14 @@ func (f *Processor) Start() {
 // Stop interrupts the processor, canceling all the pending operations.
 // Stop waits until all the internal goroutines have finished.
 func (f *Processor) Stop() {
+	f.quitMu.Lock()
+	f.stopped = true
 	close(f.quit)
+	f.quitMu.Unlock()
 	f.eventsSemaphore.Terminate()
 	f.wg.Wait()
 	f.buffer.Clear()
+	f.releaseQuarantined(f.quarantine.Clear(), eventcheck.ErrDroppedEvent)
 }
 
 // Overloaded returns true if too much events are being processed or requested
@@ -103,6 +135,12 @@ type checkRes struct {
 }
 
 func (f *Processor) Enqueue(peer string, events dag.Events, ordered bool, notifyAnnounces func(hash.Events), done func()) error {
+	if f.callback.PeerBanned != nil && f.callback.PeerBanned(peer) {
+		return ErrPeerBanned
+	}
+	if f.callback.PeerThrottled != nil && f.Overloaded() && f.callback.PeerThrottled(peer) {
+		return ErrBusy
+	}
 	if !f.eventsSemaphore.Acquire(events.Metric(), f.cfg.EventsSemaphoreTimeout) {
 		return ErrBusy
 	}
@@ -165,6 +203,18 @@ func (f *Processor) Enqueue(peer string, events dag.Events, ordered bool, notify
 }
 
 func (f *Processor) process(peer string, event dag.Event, resErr error) (toRequest hash.Events) {
+	// store event if it's of the next epoch
+	if resErr == epochcheck.ErrNotRelevant && f.callback.Epoch != nil && event.Epoch() == f.callback.Epoch()+1 {
+		err := f.quarantine.Add(event, peer)
+		if err == nil {
+			// the quarantine has its own limit, the event will acquire the semaphore again when re-injected
+			f.eventsSemaphore.Release(dag.Metric{Num: 1, Size: uint64(event.Size())})
+			return hash.Events{}
+		}
+		if err == eventcheck.ErrDuplicateEvent {
+			resErr = err
+		}
+	}
 	// release event if failed validation
 	if resErr != nil {
 		f.callback.Event.Released(event, peer, resErr)
@@ -185,18 +235,112 @@ func (f *Processor) process(peer string, event dag.Event, resErr error) (toReque
 	return hash.Events{}
 }
 
+// processEvent connects the event, which is ordered by the buffer.
+// Forks are checked right before the connection, so forks within a batch of events are detected too.
+func (f *Processor) processEvent(e dag.Event) error {
+	if f.forkcheck == nil {
+		return f.callback.Event.Process(e)
+	}
+	if err := f.forkcheck.Validate(e); err != nil {
+		return err
+	}
+	if err := f.callback.Event.Process(e); err != nil {
+		return err
+	}
+	f.forkcheck.Add(e)
+	return nil
+}
+
+// OnEventConnected should be called for the events which are connected not by the processor,
+// so that the buffered events which wait for them are processed
+func (f *Processor) OnEventConnected(id hash.Event) {
+	f.buffer.OnConnected(id)
+}
+
 func (f *Processor) IsBuffered(id hash.Event) bool {
 	return f.buffer.IsBuffered(id)
 }
 
+// OnEpochSealed should be called after the epoch is sealed, i.e. when the new epoch is the current one.
+// The quarantined events of the epoch are re-injected into the processor, so they are validated again.
+// The quarantined events of older epochs are released, and the disk tier of the events buffer is erased.
+// It's safe to call it from the Process callback, because the events are re-injected asynchronously.
+func (f *Processor) OnEpochSealed(epoch idx.Epoch) {
+	f.buffer.OnEpochSealed(epoch)
+	taken, stale := f.quarantine.Take(epoch)
+	f.releaseQuarantined(stale, epochcheck.ErrNotRelevant)
+	if len(taken) == 0 {
+		return
+	}
+	byPeer := make(map[string]dag.Events)
+	var peers []string
+	for _, qe := range taken {
+		if byPeer[qe.peer] == nil {
+			peers = append(peers, qe.peer)
+		}
+		byPeer[qe.peer] = append(byPeer[qe.peer], qe.e)
+	}
+	// the goroutine must not be added to the wait group once Stop is waiting for it
+	f.quitMu.Lock()
+	defer f.quitMu.Unlock()
+	if f.stopped {
+		f.releaseQuarantined(taken, eventcheck.ErrDroppedEvent)
+		return
+	}
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		for _, peer := range peers {
+			if err := f.Enqueue(peer, byPeer[peer], false, nil, nil); err != nil {
+				for _, e := range byPeer[peer] {
+					f.releaseQuarantined([]quarantined{{e, peer}}, err)
+				}
+			}
+		}
+	}()
+}
+
+// releaseQuarantined releases the events which don't hold the semaphore
+func (f *Processor) releaseQuarantined(events []quarantined, err error) {
+	if f.released == nil {
+		return
+	}
+	for _, qe := range events {
+		f.released(qe.e, qe.peer, err)
+	}
+}
+
 func (f *Processor) Clear() {
 	f.buffer.Clear()
+	f.releaseQuarantined(f.quarantine.Clear(), eventcheck.ErrDroppedEvent)
+}
+
+// TotalQuarantined returns the total metric of the events of the next epoch, which are stored until the epoch is sealed
+func (f *Processor) TotalQuarantined() dag.Metric {
+	return f.quarantine.Total()
 }
 
 func (f *Processor) TotalBuffered() dag.Metric {
 	return f.buffer.Total()
 }
 
+// SetOverflow sets a disk tier of the events buffer, see dagordering.EventsBuffer.SetOverflow.
+// It should be called before Start.
+func (f *Processor) SetOverflow(overflow *dagordering.Overflow) {
+	f.buffer.SetOverflow(overflow)
+}
+
+// SetForkcheck sets a fork detector, which checks the events before they are processed,
+// and indexes the processed events. It should be called before Start.
+func (f *Processor) SetForkcheck(checker *forkcheck.Checker) {
+	f.forkcheck = checker
+}
+
+// TotalOverflowed returns the total encoded size and number of events in the disk tier of the events buffer
+func (f *Processor) TotalOverflowed() dag.Metric {
+	return f.buffer.TotalOverflowed()
+}
+
 func (f *Processor) TasksCount() int {
 	return f.orderedInserter.TasksCount() + f.checker.TasksCount()
 }
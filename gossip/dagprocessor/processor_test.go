@@ -8,10 +8,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/forkcheck"
+	"github.com/Fantom-foundation/lachesis-base/gossip/dagordering"
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
 	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
 	"github.com/Fantom-foundation/lachesis-base/utils/datasemaphore"
 )
@@ -306,3 +311,220 @@ func testProcessorReleasing(t *testing.T, maxEvents int, try int64) {
 		t.Fatal("not all the events were released", len(ordered), released)
 	}
 }
+
+func TestProcessorBannedPeer(t *testing.T) {
+	semaphore := datasemaphore.New(dag.Metric{Num: 10, Size: 10000}, func(received dag.Metric, processing dag.Metric, releasing dag.Metric) {
+		t.Fatal("events semaphore inconsistency")
+	})
+	processor := New(semaphore, DefaultConfig(cachescale.Identity), Callback{
+		PeerBanned: func(peer string) bool {
+			return peer == "banned"
+		},
+	})
+	e := &tdag.TestEvent{}
+	if err := processor.Enqueue("banned", dag.Events{e}, false, nil, nil); err != ErrPeerBanned {
+		t.Fatal("events of banned peer aren't rejected", err)
+	}
+	if semaphore.Processing().Num != 0 {
+		t.Fatal("events of banned peer acquired the semaphore")
+	}
+}
+
+func TestProcessorForkcheck(t *testing.T) {
+	require := require.New(t)
+
+	newEvent := func(id byte) *tdag.TestEvent {
+		e := &tdag.TestEvent{}
+		e.SetEpoch(1)
+		e.SetCreator(1)
+		e.SetSeq(1)
+		e.SetLamport(1)
+		e.SetID([24]byte{id})
+		return e
+	}
+	a := newEvent(1)
+	fork := newEvent(2)
+
+	semaphore := datasemaphore.New(dag.Metric{Num: 10, Size: 10000}, func(received dag.Metric, processing dag.Metric, releasing dag.Metric) {
+		t.Fatal("events semaphore inconsistency")
+	})
+	mu := sync.Mutex{}
+	processed := make(map[hash.Event]dag.Event)
+	released := make(map[hash.Event]error)
+	processor := New(semaphore, DefaultConfig(cachescale.Identity), Callback{
+		Event: EventCallback{
+			Process: func(e dag.Event) error {
+				mu.Lock()
+				defer mu.Unlock()
+				processed[e.ID()] = e
+				return nil
+			},
+			Released: func(e dag.Event, peer string, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				released[e.ID()] = err
+			},
+			Exists: func(id hash.Event) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return processed[id] != nil
+			},
+			Get: func(id hash.Event) dag.Event {
+				mu.Lock()
+				defer mu.Unlock()
+				return processed[id]
+			},
+			CheckParents: func(e dag.Event, parents dag.Events) error {
+				return nil
+			},
+			CheckParentless: func(e dag.Event, checked func(error)) {
+				checked(nil)
+			},
+		},
+		HighestLamport: func() idx.Lamport {
+			return 0
+		},
+		Epoch: func() idx.Epoch {
+			return 1
+		},
+	})
+	checker := forkcheck.New(nil)
+	processor.SetForkcheck(checker)
+	processor.Start()
+	defer processor.Stop()
+
+	// both events are in a single batch, so neither is indexed when the batch is validated
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	require.NoError(processor.Enqueue("peer", dag.Events{a, fork}, true, nil, wg.Done))
+	wg.Wait()
+	require.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(released) == 2
+	}, time.Second*5, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(processed, 1)
+	require.Contains(processed, a.ID())
+	require.NoError(released[a.ID()])
+	evidence, ok := released[fork.ID()].(*forkcheck.ForkEvidence)
+	require.True(ok, released[fork.ID()])
+	require.Equal(a.ID(), evidence.Existing)
+	require.Equal(fork.ID(), evidence.Conflicting)
+
+	// the processed event is indexed
+	_, ok = checker.Validate(newEvent(3)).(*forkcheck.ForkEvidence)
+	require.True(ok)
+}
+
+// TestProcessorOverflow checks that the events which are moved into the disk tier don't hold the semaphore,
+// and that they are released once, after they are processed
+func TestProcessorOverflow(t *testing.T) {
+	require := require.New(t)
+
+	var ordered dag.Events
+	_ = tdag.ForEachRandEvent(tdag.GenNodes(5), 20, 3, rand.New(rand.NewSource(0)), tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+		},
+		Build: func(e dag.MutableEvent, name string) error {
+			e.SetEpoch(1)
+			e.SetFrame(idx.Frame(e.Seq()))
+			return nil
+		},
+	})
+	byID := make(map[hash.Event]dag.Event)
+	var highestLamport idx.Lamport
+	for _, e := range ordered {
+		byID[e.ID()] = e
+		if highestLamport < e.Lamport() {
+			highestLamport = e.Lamport()
+		}
+	}
+
+	semaphore := datasemaphore.New(dag.Metric{Num: 1000, Size: 1000000}, func(received dag.Metric, processing dag.Metric, releasing dag.Metric) {
+		t.Fatal("events semaphore inconsistency")
+	})
+	config := DefaultConfig(cachescale.Identity)
+	config.EventsBufferLimit = dag.Metric{Num: 3, Size: 1000000}
+
+	mu := sync.Mutex{}
+	processed := make(map[hash.Event]dag.Event)
+	released := make(map[hash.Event]int)
+	processor := New(semaphore, config, Callback{
+		Event: EventCallback{
+			Process: func(e dag.Event) error {
+				mu.Lock()
+				defer mu.Unlock()
+				processed[e.ID()] = e
+				return nil
+			},
+			Released: func(e dag.Event, peer string, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					t.Error("event is dropped", e.String(), err)
+				}
+				if processed[e.ID()] == nil {
+					t.Error("event is released before it's processed", e.String())
+				}
+				released[e.ID()]++
+			},
+			Exists: func(id hash.Event) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return processed[id] != nil
+			},
+			Get: func(id hash.Event) dag.Event {
+				mu.Lock()
+				defer mu.Unlock()
+				return processed[id]
+			},
+			CheckParents: func(e dag.Event, parents dag.Events) error {
+				return nil
+			},
+			CheckParentless: func(e dag.Event, checked func(error)) {
+				checked(nil)
+			},
+		},
+		HighestLamport: func() idx.Lamport {
+			// none of the events is spilled for being in the far future
+			return highestLamport
+		},
+	})
+	processor.SetOverflow(dagordering.NewOverflow(memorydb.New(), dagordering.DefaultOverflowConfig(), dagordering.OverflowCallback{
+		Marshal: func(e dag.Event) ([]byte, error) {
+			return e.ID().Bytes(), nil
+		},
+		Unmarshal: func(b []byte) (dag.Event, error) {
+			return byID[hash.BytesToEvent(b)], nil
+		},
+	}, func(err error) {
+		panic(err)
+	}))
+	processor.Start()
+	defer processor.Stop()
+
+	// the events are pushed in the reversed order, so most of them are moved into the disk tier
+	for i := len(ordered) - 1; i >= 0; i-- {
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		require.NoError(processor.Enqueue("peer", ordered[i:i+1], true, nil, wg.Done))
+		wg.Wait()
+	}
+	require.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(processed) == len(ordered)
+	}, time.Second*5, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, e := range ordered {
+		require.Equal(1, released[e.ID()], e.String())
+	}
+	require.Equal(dag.Metric{}, processor.TotalOverflowed())
+	require.Equal(dag.Metric{}, semaphore.Processing())
+}
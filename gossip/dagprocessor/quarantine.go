@@ -0,0 +1,105 @@
+package dagprocessor
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+var errQuarantineFull = errors.New("quarantine is full")
+
+type quarantined struct {
+	e    dag.Event
+	peer string
+}
+
+// quarantine stores events of future epochs, so that they aren't dropped and re-fetched
+// if they arrive before the current epoch is sealed. Events are keyed by ID, so an event is stored once.
+type quarantine struct {
+	limit dag.Metric
+
+	mu     sync.Mutex
+	total  dag.Metric
+	epochs map[idx.Epoch]map[hash.Event]quarantined
+}
+
+func newQuarantine(limit dag.Metric) *quarantine {
+	return &quarantine{
+		limit:  limit,
+		epochs: make(map[idx.Epoch]map[hash.Event]quarantined),
+	}
+}
+
+// Add stores the event. It returns ErrDuplicateEvent if the event is stored already,
+// or errQuarantineFull if the limit is reached.
+func (q *quarantine) Add(e dag.Event, peer string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events := q.epochs[e.Epoch()]
+	if _, ok := events[e.ID()]; ok {
+		return eventcheck.ErrDuplicateEvent
+	}
+	if q.total.Num+1 > q.limit.Num || q.total.Size+uint64(e.Size()) > q.limit.Size {
+		return errQuarantineFull
+	}
+	if events == nil {
+		events = make(map[hash.Event]quarantined)
+		q.epochs[e.Epoch()] = events
+	}
+	q.total.Num++
+	q.total.Size += uint64(e.Size())
+	events[e.ID()] = quarantined{e, peer}
+	return nil
+}
+
+// Take removes the events of the epoch and returns them.
+// Events of older epochs are removed and returned as stale.
+func (q *quarantine) Take(epoch idx.Epoch) (taken []quarantined, stale []quarantined) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for e, events := range q.epochs {
+		if e > epoch {
+			continue
+		}
+		for _, qe := range events {
+			if e == epoch {
+				taken = append(taken, qe)
+			} else {
+				stale = append(stale, qe)
+			}
+			q.total.Num--
+			q.total.Size -= uint64(qe.e.Size())
+		}
+		delete(q.epochs, e)
+	}
+	return taken, stale
+}
+
+// Clear removes all the events and returns them
+func (q *quarantine) Clear() (removed []quarantined) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, events := range q.epochs {
+		for _, qe := range events {
+			removed = append(removed, qe)
+		}
+	}
+	q.epochs = make(map[idx.Epoch]map[hash.Event]quarantined)
+	q.total = dag.Metric{}
+	return removed
+}
+
+// Total returns the total metric of the stored events
+func (q *quarantine) Total() dag.Metric {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.total
+}
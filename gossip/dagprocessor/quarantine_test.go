@@ -0,0 +1,196 @@
+package dagprocessor
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/epochcheck"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
+	"github.com/Fantom-foundation/lachesis-base/utils/datasemaphore"
+)
+
+func TestProcessorQuarantine(t *testing.T) {
+	require := require.New(t)
+
+	genEpoch := func(epoch idx.Epoch) dag.Events {
+		var events dag.Events
+		_ = tdag.ForEachRandEvent(tdag.GenNodes(5), 10, 3, nil, tdag.ForEachEvent{
+			Process: func(e dag.Event, name string) {
+				events = append(events, e)
+			},
+			Build: func(e dag.MutableEvent, name string) error {
+				e.SetEpoch(epoch)
+				e.SetFrame(idx.Frame(e.Seq()))
+				return nil
+			},
+		})
+		return events
+	}
+	next := genEpoch(2)
+	future := genEpoch(3)
+
+	semaphore := datasemaphore.New(dag.Metric{Num: 1000, Size: 1000000}, func(received dag.Metric, processing dag.Metric, releasing dag.Metric) {
+		t.Fatal("events semaphore inconsistency")
+	})
+	config := DefaultConfig(cachescale.Identity)
+	config.QuarantineLimit = dag.Metric{Num: idx.Event(len(next) - 1), Size: 1000000}
+
+	epoch := uint32(1)
+	mu := sync.Mutex{}
+	processed := make(map[hash.Event]dag.Event)
+	released := make(map[hash.Event]error)
+	processor := New(semaphore, config, Callback{
+		Event: EventCallback{
+			Process: func(e dag.Event) error {
+				mu.Lock()
+				defer mu.Unlock()
+				processed[e.ID()] = e
+				return nil
+			},
+			Released: func(e dag.Event, peer string, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					released[e.ID()] = err
+				}
+			},
+			Exists: func(id hash.Event) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return processed[id] != nil
+			},
+			Get: func(id hash.Event) dag.Event {
+				mu.Lock()
+				defer mu.Unlock()
+				return processed[id]
+			},
+			CheckParents: func(e dag.Event, parents dag.Events) error {
+				return nil
+			},
+			CheckParentless: func(e dag.Event, checked func(error)) {
+				if e.Epoch() != idx.Epoch(atomic.LoadUint32(&epoch)) {
+					checked(epochcheck.ErrNotRelevant)
+					return
+				}
+				checked(nil)
+			},
+		},
+		HighestLamport: func() idx.Lamport {
+			return 0
+		},
+		Epoch: func() idx.Epoch {
+			return idx.Epoch(atomic.LoadUint32(&epoch))
+		},
+	})
+	processor.Start()
+	defer processor.Stop()
+
+	enqueue := func(events dag.Events) {
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		require.NoError(processor.Enqueue("peer", events, true, nil, wg.Done))
+		wg.Wait()
+	}
+	enqueue(next)
+	enqueue(future)
+
+	// the last event of the next epoch exceeds the limit, events of the future epoch aren't quarantined
+	require.Equal(idx.Event(len(next)-1), processor.TotalQuarantined().Num)
+	require.Empty(processed)
+	mu.Lock()
+	require.Len(released, len(future)+1)
+	require.Equal(epochcheck.ErrNotRelevant, released[next[len(next)-1].ID()])
+	mu.Unlock()
+	require.Equal(idx.Event(0), semaphore.Processing().Num)
+
+	// a re-sent event isn't quarantined twice
+	enqueue(next[:1])
+	require.Equal(idx.Event(len(next)-1), processor.TotalQuarantined().Num)
+	mu.Lock()
+	require.Equal(eventcheck.ErrDuplicateEvent, released[next[0].ID()])
+	delete(released, next[0].ID())
+	mu.Unlock()
+
+	// the quarantined events are re-injected after the epoch is sealed
+	atomic.StoreUint32(&epoch, 2)
+	processor.OnEpochSealed(2)
+	require.Equal(idx.Event(0), processor.TotalQuarantined().Num)
+	require.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(processed) == len(next)-1
+	}, time.Second*5, time.Millisecond)
+	for _, e := range next[:len(next)-1] {
+		require.Contains(processed, e.ID())
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	require := require.New(t)
+
+	e := func(epoch idx.Epoch, id byte) dag.Event {
+		te := &tdag.TestEvent{}
+		te.SetEpoch(epoch)
+		te.SetID([24]byte{id})
+		return te
+	}
+	size := uint64(e(1, 1).Size())
+	q := newQuarantine(dag.Metric{Num: 3, Size: 3 * size})
+	require.NoError(q.Add(e(2, 1), "a"))
+	require.NoError(q.Add(e(3, 2), "b"))
+	require.Equal(eventcheck.ErrDuplicateEvent, q.Add(e(3, 2), "c"))
+	require.NoError(q.Add(e(4, 3), "c"))
+	require.Equal(errQuarantineFull, q.Add(e(4, 4), "c"))
+	require.Equal(dag.Metric{Num: 3, Size: 3 * size}, q.Total())
+
+	taken, stale := q.Take(3)
+	require.Len(taken, 1)
+	require.Equal("b", taken[0].peer)
+	require.Len(stale, 1)
+	require.Equal("a", stale[0].peer)
+	require.Equal(dag.Metric{Num: 1, Size: size}, q.Total())
+
+	require.Len(q.Clear(), 1)
+	require.Equal(dag.Metric{}, q.Total())
+}
+
+func TestProcessorEpochSealedAfterStop(t *testing.T) {
+	require := require.New(t)
+
+	e := &tdag.TestEvent{}
+	e.SetEpoch(2)
+	e.SetID([24]byte{1})
+
+	semaphore := datasemaphore.New(dag.Metric{Num: 10, Size: 10000}, func(received dag.Metric, processing dag.Metric, releasing dag.Metric) {
+		t.Fatal("events semaphore inconsistency")
+	})
+	mu := sync.Mutex{}
+	released := make(map[hash.Event]error)
+	processor := New(semaphore, DefaultConfig(cachescale.Identity), Callback{
+		Event: EventCallback{
+			Released: func(e dag.Event, peer string, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				released[e.ID()] = err
+			},
+		},
+	})
+	processor.Start()
+	processor.Stop()
+
+	// an event which was quarantined concurrently with Stop isn't re-injected
+	require.NoError(processor.quarantine.Add(e, "peer"))
+	processor.OnEpochSealed(2)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(eventcheck.ErrDroppedEvent, released[e.ID()])
+}
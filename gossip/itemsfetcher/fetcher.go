@@ -65,6 +65,10 @@ type Callback struct {
 	// FilterInterested returns only item which may be requested.
 	OnlyInterested func(ids []interface{}) []interface{}
 	Suspend        func() bool
+	// PeerBanned and PeerThrottled are optional, announces of banned peers are ignored,
+	// and announces of throttled peers are ignored if the fetcher is overloaded
+	PeerBanned    func(peer string) bool
+	PeerThrottled func(peer string) bool
 }
 
 // New creates a item fetcher to retrieve items based on hash announcements.
@@ -109,9 +113,20 @@ func (f *Fetcher) Overloaded() bool {
 		f.announces.Len() > f.cfg.HashLimit/2
 }
 
+// Backlog returns the number of announced items, which are scheduled for fetching or are being fetched
+func (f *Fetcher) Backlog() int {
+	return f.announces.Len()
+}
+
 // NotifyAnnounces announces the fetcher of the potential availability of a new item in
 // the network.
 func (f *Fetcher) NotifyAnnounces(peer string, ids []interface{}, time time.Time, fetchItems ItemsRequesterFn) error {
+	if f.callback.PeerBanned != nil && f.callback.PeerBanned(peer) {
+		return nil
+	}
+	if f.callback.PeerThrottled != nil && f.Overloaded() && f.callback.PeerThrottled(peer) {
+		return nil
+	}
 	// divide big batch into smaller ones
 	for start := 0; start < len(ids); start += f.cfg.MaxBatch {
 		end := len(ids)
@@ -0,0 +1,34 @@
+package peerscore
+
+import "time"
+
+type Config struct {
+	// Penalties which are added to the peer score for an error of the severity
+	BenignPenalty    float64
+	NoisyPenalty     float64
+	MaliciousPenalty float64
+
+	// HalfLife is the time during which the score decays in half. Zero value disables the decay
+	HalfLife time.Duration
+
+	// ThrottleScore is the score from which a peer is throttled. Zero value disables throttling
+	ThrottleScore float64
+	// BanScore is the score from which a peer is banned. Zero value disables banning
+	BanScore float64
+	// MinScore is the score below which a peer score is removed by Prune
+	MinScore float64
+}
+
+// DefaultConfig for livenet.
+// A peer is banned for a single malicious error, and throttled for 100 noisy errors in a short period.
+func DefaultConfig() Config {
+	return Config{
+		BenignPenalty:    0,
+		NoisyPenalty:     1,
+		MaliciousPenalty: 1000,
+		HalfLife:         10 * time.Minute,
+		ThrottleScore:    100,
+		BanScore:         1000,
+		MinScore:         0.1,
+	}
+}
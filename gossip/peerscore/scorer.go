@@ -0,0 +1,249 @@
+package peerscore
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/basiccheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/epochcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/forkcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/framecheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/limitscheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/parentscheck"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+)
+
+// Severity is a class of peer misbehaviour
+type Severity int
+
+const (
+	// Benign errors happen with honest peers in normal conditions, e.g. duplicated events
+	Benign Severity = iota
+	// Noisy errors happen with honest peers if they are lagging or if the node is overloaded, e.g. spilled events
+	Noisy
+	// Malicious errors cannot be caused by an honest peer, e.g. events with wrong Lamport time
+	Malicious
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Benign:
+		return "benign"
+	case Noisy:
+		return "noisy"
+	case Malicious:
+		return "malicious"
+	}
+	return "unknown"
+}
+
+// Decision is a restriction of a peer
+type Decision int
+
+const (
+	// Allow means that a peer isn't restricted
+	Allow Decision = iota
+	// Throttle means that requests of a peer should be served only if the node isn't busy
+	Throttle
+	// Ban means that a peer should be disconnected and its events should be dropped
+	Ban
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Allow:
+		return "allow"
+	case Throttle:
+		return "throttle"
+	case Ban:
+		return "ban"
+	}
+	return "unknown"
+}
+
+// Classifier returns severity of an error
+type Classifier func(err error) Severity
+
+var maliciousErrors = []error{
+	basiccheck.ErrNoParents,
+	basiccheck.ErrNotInited,
+	basiccheck.ErrHugeValue,
+	basiccheck.ErrDoubleParents,
+	epochcheck.ErrAuth,
+	parentscheck.ErrWrongSeq,
+	parentscheck.ErrWrongLamport,
+	parentscheck.ErrWrongSelfParent,
+	limitscheck.ErrTooManyParents,
+	limitscheck.ErrTooBigSize,
+	limitscheck.ErrTooHighSeq,
+	limitscheck.ErrLamportGap,
+	limitscheck.ErrFrameJump,
+	framecheck.ErrFrameBelowSelfParent,
+	framecheck.ErrFrameAboveParents,
+	framecheck.ErrFirstEventFrame,
+}
+
+// benignErrors include the errors which are caused by someone else than the peer.
+// An honest peer relays forks of other validators and their descendants,
+// and the events which are dropped by the node aren't the peer's fault
+var benignErrors = []error{
+	eventcheck.ErrDuplicateEvent,
+	eventcheck.ErrAlreadyConnectedEvent,
+	eventcheck.ErrDroppedEvent,
+	eventcheck.ErrInvalidParent,
+}
+
+// DefaultClassifier classifies the errors of eventcheck packages.
+// Fork evidence is benign, because the fork is made by the event creator, not by the peer which relays it.
+// Unknown errors are noisy, because they may be caused by the node itself.
+func DefaultClassifier(err error) Severity {
+	for _, e := range benignErrors {
+		if errors.Is(err, e) {
+			return Benign
+		}
+	}
+	var evidence *forkcheck.ForkEvidence
+	if errors.As(err, &evidence) {
+		return Benign
+	}
+	for _, e := range maliciousErrors {
+		if errors.Is(err, e) {
+			return Malicious
+		}
+	}
+	return Noisy
+}
+
+type peerScore struct {
+	score   float64
+	updated time.Time
+}
+
+// Scorer maintains decaying misbehaviour scores of peers
+type Scorer struct {
+	cfg      Config
+	classify Classifier
+	now      func() time.Time
+
+	mu    sync.Mutex
+	peers map[string]*peerScore
+}
+
+// New creates Scorer instance. If classify is nil, DefaultClassifier is used.
+func New(cfg Config, classify Classifier) *Scorer {
+	if classify == nil {
+		classify = DefaultClassifier
+	}
+	return &Scorer{
+		cfg:      cfg,
+		classify: classify,
+		now:      time.Now,
+		peers:    make(map[string]*peerScore),
+	}
+}
+
+// decayed returns the score at the time
+func (s *Scorer) decayed(p *peerScore, now time.Time) float64 {
+	if s.cfg.HalfLife == 0 || !now.After(p.updated) {
+		return p.score
+	}
+	return p.score * math.Exp2(-float64(now.Sub(p.updated))/float64(s.cfg.HalfLife))
+}
+
+func (s *Scorer) penalty(severity Severity) float64 {
+	switch severity {
+	case Noisy:
+		return s.cfg.NoisyPenalty
+	case Malicious:
+		return s.cfg.MaliciousPenalty
+	}
+	return s.cfg.BenignPenalty
+}
+
+// Report adds the penalty of the error to the peer score, and returns the error severity
+func (s *Scorer) Report(peer string, err error) Severity {
+	severity := s.classify(err)
+	penalty := s.penalty(severity)
+	if penalty == 0 {
+		return severity
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	p := s.peers[peer]
+	if p == nil {
+		p = &peerScore{}
+		s.peers[peer] = p
+	}
+	p.score = s.decayed(p, now) + penalty
+	p.updated = now
+	return severity
+}
+
+// OnReleased may be used as the Released callback of dagprocessor, it reports the errors of the events
+func (s *Scorer) OnReleased(e dag.Event, peer string, err error) {
+	if err == nil || peer == "" {
+		return
+	}
+	s.Report(peer, err)
+}
+
+// Score returns the current misbehaviour score of the peer
+func (s *Scorer) Score(peer string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.peers[peer]
+	if p == nil {
+		return 0
+	}
+	return s.decayed(p, s.now())
+}
+
+// Decision returns the restriction of the peer by its current score
+func (s *Scorer) Decision(peer string) Decision {
+	score := s.Score(peer)
+	if s.cfg.BanScore != 0 && score >= s.cfg.BanScore {
+		return Ban
+	}
+	if s.cfg.ThrottleScore != 0 && score >= s.cfg.ThrottleScore {
+		return Throttle
+	}
+	return Allow
+}
+
+// Banned returns true if the peer should be banned
+func (s *Scorer) Banned(peer string) bool {
+	return s.Decision(peer) == Ban
+}
+
+// Throttled returns true if the peer should be throttled or banned
+func (s *Scorer) Throttled(peer string) bool {
+	return s.Decision(peer) != Allow
+}
+
+// Forget removes the peer score
+func (s *Scorer) Forget(peer string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.peers, peer)
+}
+
+// Prune removes the scores which have decayed below MinScore, so that memory isn't leaked by disconnected peers
+func (s *Scorer) Prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for peer, p := range s.peers {
+		if s.decayed(p, now) < s.cfg.MinScore {
+			delete(s.peers, peer)
+		}
+	}
+}
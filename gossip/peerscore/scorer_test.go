@@ -0,0 +1,172 @@
+package peerscore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/eventcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/epochcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/forkcheck"
+	"github.com/Fantom-foundation/lachesis-base/eventcheck/parentscheck"
+	"github.com/Fantom-foundation/lachesis-base/gossip/dagprocessor"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
+	"github.com/Fantom-foundation/lachesis-base/utils/datasemaphore"
+)
+
+func TestDefaultClassifier(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(Benign, DefaultClassifier(eventcheck.ErrDuplicateEvent))
+	require.Equal(Benign, DefaultClassifier(eventcheck.ErrAlreadyConnectedEvent))
+	require.Equal(Noisy, DefaultClassifier(eventcheck.ErrSpilledEvent))
+	require.Equal(Noisy, DefaultClassifier(epochcheck.ErrNotRelevant))
+	require.Equal(Noisy, DefaultClassifier(errors.New("unknown")))
+	require.Equal(Malicious, DefaultClassifier(parentscheck.ErrWrongLamport))
+	require.Equal(Malicious, DefaultClassifier(fmt.Errorf("wrapped: %w", parentscheck.ErrWrongLamport)))
+	require.Equal(Benign, DefaultClassifier(&forkcheck.ForkEvidence{}))
+	require.Equal(Benign, DefaultClassifier(eventcheck.ErrInvalidParent))
+	require.Equal(Benign, DefaultClassifier(eventcheck.ErrDroppedEvent))
+}
+
+func TestScorer(t *testing.T) {
+	require := require.New(t)
+
+	cfg := DefaultConfig()
+	now := time.Unix(1, 0)
+	s := New(cfg, nil)
+	s.now = func() time.Time {
+		return now
+	}
+
+	require.Equal(Benign, s.Report("a", eventcheck.ErrDuplicateEvent))
+	require.Equal(0.0, s.Score("a"))
+	require.Equal(Allow, s.Decision("a"))
+
+	// noisy errors throttle
+	for i := 0; i < 100; i++ {
+		s.OnReleased(nil, "a", eventcheck.ErrSpilledEvent)
+	}
+	s.OnReleased(nil, "a", nil)
+	s.OnReleased(nil, "", parentscheck.ErrWrongLamport)
+	require.Equal(100.0, s.Score("a"))
+	require.Equal(Throttle, s.Decision("a"))
+	require.True(s.Throttled("a"))
+	require.False(s.Banned("a"))
+
+	// decay
+	now = now.Add(cfg.HalfLife)
+	require.InDelta(50.0, s.Score("a"), 1e-9)
+	require.Equal(Allow, s.Decision("a"))
+
+	// malicious errors ban
+	require.Equal(Malicious, s.Report("b", parentscheck.ErrWrongLamport))
+	require.Equal(Ban, s.Decision("b"))
+	require.True(s.Banned("b"))
+	require.True(s.Throttled("b"))
+	require.Equal(Allow, s.Decision("c"))
+
+	// prune
+	now = now.Add(cfg.HalfLife * 20)
+	s.Prune()
+	require.Empty(s.peers)
+
+	s.Report("b", parentscheck.ErrWrongLamport)
+	s.Forget("b")
+	require.Equal(Allow, s.Decision("b"))
+}
+
+// TestScorer_HonestRelay checks that a peer which relays a fork of another validator isn't penalized,
+// as well as for its events which are dropped when the processor stops
+func TestScorer_HonestRelay(t *testing.T) {
+	require := require.New(t)
+
+	newEvent := func(seq idx.Event, id byte, parents ...hash.Event) *tdag.TestEvent {
+		e := &tdag.TestEvent{}
+		e.SetEpoch(1)
+		e.SetCreator(1)
+		e.SetSeq(seq)
+		e.SetLamport(idx.Lamport(seq))
+		e.SetParents(parents)
+		e.SetID([24]byte{id})
+		return e
+	}
+	a := newEvent(1, 1)
+	fork := newEvent(1, 2)
+	// the parent of the event isn't known, so it waits in the buffer until the processor stops
+	incomplete := newEvent(2, 3, hash.Event{4})
+
+	s := New(DefaultConfig(), nil)
+	semaphore := datasemaphore.New(dag.Metric{Num: 10, Size: 10000}, func(received dag.Metric, processing dag.Metric, releasing dag.Metric) {
+		t.Fatal("events semaphore inconsistency")
+	})
+	mu := sync.Mutex{}
+	processed := make(map[hash.Event]dag.Event)
+	var released []error
+	processor := dagprocessor.New(semaphore, dagprocessor.DefaultConfig(cachescale.Identity), dagprocessor.Callback{
+		Event: dagprocessor.EventCallback{
+			Process: func(e dag.Event) error {
+				mu.Lock()
+				defer mu.Unlock()
+				processed[e.ID()] = e
+				return nil
+			},
+			Released: func(e dag.Event, peer string, err error) {
+				mu.Lock()
+				released = append(released, err)
+				mu.Unlock()
+				s.OnReleased(e, peer, err)
+			},
+			Exists: func(id hash.Event) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return processed[id] != nil
+			},
+			Get: func(id hash.Event) dag.Event {
+				mu.Lock()
+				defer mu.Unlock()
+				return processed[id]
+			},
+			CheckParents: func(e dag.Event, parents dag.Events) error {
+				return nil
+			},
+			CheckParentless: func(e dag.Event, checked func(error)) {
+				checked(nil)
+			},
+		},
+		HighestLamport: func() idx.Lamport {
+			return 0
+		},
+		PeerBanned: s.Banned,
+	})
+	processor.SetForkcheck(forkcheck.New(nil))
+	processor.Start()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	require.NoError(processor.Enqueue("relay", dag.Events{a, fork, incomplete}, true, nil, wg.Done))
+	wg.Wait()
+	require.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(released) == 2
+	}, time.Second*5, time.Millisecond)
+	processor.Stop()
+
+	mu.Lock()
+	require.Len(released, 3)
+	_, ok := released[1].(*forkcheck.ForkEvidence)
+	require.True(ok, released[1])
+	require.Equal(eventcheck.ErrDroppedEvent, released[2])
+	mu.Unlock()
+	require.Equal(0.0, s.Score("relay"))
+	require.False(s.Banned("relay"))
+}
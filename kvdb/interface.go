@@ -43,6 +43,12 @@ type Writer interface {
 	ethdb.KeyValueWriter
 }
 
+// SyncWriter wraps the SyncPut method of a backing data store.
+type SyncWriter interface {
+	// SyncPut inserts the given value into the key-value data store, and waits until it's written to disk.
+	SyncPut(key []byte, value []byte) error
+}
+
 // Reader wraps the Has and get method of a backing data store.
 type Reader interface {
 	ethdb.KeyValueReader
@@ -213,6 +213,11 @@ func (db *Database) Put(key []byte, value []byte) error {
 	return db.underlying.Put(key, value, nil)
 }
 
+// SyncPut inserts the given value into the key-value store, and waits until it's written to disk.
+func (db *Database) SyncPut(key []byte, value []byte) error {
+	return db.underlying.Put(key, value, &opt.WriteOptions{Sync: true})
+}
+
 // Delete removes the key from the key-value store.
 func (db *Database) Delete(key []byte) error {
 	return db.underlying.Delete(key, nil)
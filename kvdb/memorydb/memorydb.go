@@ -22,6 +22,12 @@ func New() *Database {
 	}
 }
 
+// SyncPut inserts the given value into the key-value store.
+// Memory database is never written to disk, so it's the same as Put.
+func (db *Database) SyncPut(key []byte, value []byte) error {
+	return db.Put(key, value)
+}
+
 // NewWithDrop is the same as New, but defines onDrop callback.
 func NewWithDrop(drop func()) *Database {
 	return &Database{
@@ -9,6 +9,14 @@ type IteratedReader struct {
 	underlying kvdb.IteratedReader
 }
 
+// NewReadonly wraps the underlying reader, so all the table's data is read with a prefix
+func NewReadonly(db kvdb.IteratedReader, prefix []byte) *IteratedReader {
+	return &IteratedReader{
+		prefix:     prefix,
+		underlying: db,
+	}
+}
+
 func (t *IteratedReader) Has(key []byte) (bool, error) {
 	return t.underlying.Has(prefixed(key, t.prefix))
 }
@@ -32,6 +32,30 @@ func MigrateTables(s interface{}, db kvdb.Store) {
 	}
 }
 
+// MigrateReaders sets target fields to read-only database tables.
+func MigrateReaders(s interface{}, db kvdb.IteratedReader) {
+	value := reflect.ValueOf(s).Elem()
+
+	var keys uniqKeys
+	defer keys.Check()
+
+	for i := 0; i < value.NumField(); i++ {
+		if prefix := value.Type().Field(i).Tag.Get("table"); prefix != "" && prefix != "-" {
+
+			field := value.Field(i)
+			var val reflect.Value
+			if db != nil {
+				keys.Add(prefix)
+				table := NewReadonly(db, []byte(prefix))
+				val = reflect.ValueOf(table)
+			} else {
+				val = reflect.Zero(field.Type())
+			}
+			field.Set(val)
+		}
+	}
+}
+
 // OpenTables sets target fields to database tables.
 func OpenTables(s interface{}, producer kvdb.DBProducer, baseName string) error {
 	value := reflect.ValueOf(s).Elem()
@@ -5,6 +5,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/vecfc"
+	"github.com/Fantom-foundation/lachesis-base/vecmem"
 )
 
 type VectorSeqToDagIndexSeq struct {
@@ -38,3 +39,29 @@ type VectorToDagIndexer struct {
 func (v *VectorToDagIndexer) GetMergedHighestBefore(id hash.Event) dagidx.HighestBeforeSeq {
 	return VectorSeqToDagIndexSeq{v.Index.GetMergedHighestBefore(id)}
 }
+
+type ReadOnlyVectorToDagIndexer struct {
+	*vecfc.ReadOnlyIndex
+}
+
+func (v *ReadOnlyVectorToDagIndexer) GetMergedHighestBefore(id hash.Event) dagidx.HighestBeforeSeq {
+	return VectorSeqToDagIndexSeq{v.ReadOnlyIndex.GetMergedHighestBefore(id)}
+}
+
+type MemVectorSeqToDagIndexSeq struct {
+	*vecmem.HighestBeforeSeq
+}
+
+// Get i's position in the vector clock
+func (b MemVectorSeqToDagIndexSeq) Get(i idx.Validator) dagidx.Seq {
+	seq := b.HighestBeforeSeq.Get(i)
+	return &BranchSeq{seq}
+}
+
+type MemVectorToDagIndexer struct {
+	*vecmem.Index
+}
+
+func (v *MemVectorToDagIndexer) GetMergedHighestBefore(id hash.Event) dagidx.HighestBeforeSeq {
+	return MemVectorSeqToDagIndexSeq{v.Index.GetMergedHighestBefore(id)}
+}
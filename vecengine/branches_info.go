@@ -1,6 +1,7 @@
 package vecengine
 
 import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/inter/pos"
 )
@@ -12,6 +13,28 @@ type BranchesInfo struct {
 	BranchIDByCreators  [][]idx.Validator // validator idx -> list of branch IDs
 }
 
+// Branch describes a global branch of a validator
+type Branch struct {
+	ID         idx.Validator
+	FirstSeq   idx.Event
+	LastSeq    idx.Event
+	FirstEvent hash.Event // zero if unknown
+}
+
+// CreatorBranches describes all the global branches of a validator
+type CreatorBranches struct {
+	Creator  idx.ValidatorID
+	Branches []Branch
+	// ForkObservers are events which have observed the fork by themselves, not via their parents
+	ForkObservers hash.Events
+}
+
+type newBranch struct {
+	creator    idx.ValidatorID
+	branchID   idx.Validator
+	firstEvent hash.Event
+}
+
 // InitBranchesInfo loads BranchesInfo from store
 func (vi *Engine) InitBranchesInfo() {
 	if vi.bi == nil {
@@ -51,3 +74,68 @@ func (vi *Engine) AtLeastOneFork() bool {
 func (vi *Engine) BranchesInfo() *BranchesInfo {
 	return vi.bi
 }
+
+// GetCreatorBranches returns all the global branches of a validator, and the events which observed its fork.
+// Returns false if creator isn't a validator of the epoch.
+func (vi *Engine) GetCreatorBranches(creator idx.ValidatorID) (CreatorBranches, bool) {
+	vi.InitBranchesInfo()
+
+	creatorIdx, ok := vi.validatorIdxs[creator]
+	if !ok {
+		return CreatorBranches{}, false
+	}
+	res := CreatorBranches{
+		Creator:  creator,
+		Branches: make([]Branch, 0, len(vi.bi.BranchIDByCreators[creatorIdx])),
+	}
+	for _, branchID := range vi.bi.BranchIDByCreators[creatorIdx] {
+		firstSeq, firstEvent := vi.getBranchFirstEvent(branchID)
+		res.Branches = append(res.Branches, Branch{
+			ID:         branchID,
+			FirstSeq:   firstSeq,
+			LastSeq:    vi.bi.BranchIDLastSeq[branchID],
+			FirstEvent: firstEvent,
+		})
+	}
+	if len(res.Branches) > 1 {
+		res.ForkObservers = vi.getForkObservers(creatorIdx)
+	}
+	return res, true
+}
+
+// GetAllBranches returns global branches of each validator, ordered by validator idx
+func (vi *Engine) GetAllBranches() []CreatorBranches {
+	res := make([]CreatorBranches, 0, vi.validators.Len())
+	for _, creator := range vi.validators.SortedIDs() {
+		branches, _ := vi.GetCreatorBranches(creator)
+		res = append(res, branches)
+	}
+	return res
+}
+
+func (vi *Engine) notifyNewBranches() {
+	newBranches := vi.newBranches
+	vi.newBranches = nil
+	if vi.callback.OnNewBranch == nil {
+		return
+	}
+	for _, b := range newBranches {
+		vi.callback.OnNewBranch(b.creator, b.branchID, b.firstEvent)
+	}
+}
+
+// Copy returns a deep copy of BranchesInfo
+func (b *BranchesInfo) Copy() *BranchesInfo {
+	cp := &BranchesInfo{
+		BranchIDLastSeq:     make([]idx.Event, len(b.BranchIDLastSeq)),
+		BranchIDCreatorIdxs: make([]idx.Validator, len(b.BranchIDCreatorIdxs)),
+		BranchIDByCreators:  make([][]idx.Validator, len(b.BranchIDByCreators)),
+	}
+	copy(cp.BranchIDLastSeq, b.BranchIDLastSeq)
+	copy(cp.BranchIDCreatorIdxs, b.BranchIDCreatorIdxs)
+	for i, branches := range b.BranchIDByCreators {
+		cp.BranchIDByCreators[i] = make([]idx.Validator, len(branches))
+		copy(cp.BranchIDByCreators[i], branches)
+	}
+	return cp
+}
@@ -22,6 +22,8 @@ type Callbacks struct {
 	NewLowestAfter   func(idx.Validator) LowestAfterI
 	OnDbReset        func(db kvdb.Store)
 	OnDropNotFlushed func()
+	// OnNewBranch is called for each new fork branch of a creator, once it's flushed
+	OnNewBranch func(creator idx.ValidatorID, branchID idx.Validator, firstEvent hash.Event)
 }
 
 type Engine struct {
@@ -30,15 +32,21 @@ type Engine struct {
 	validatorIdxs map[idx.ValidatorID]idx.Validator
 
 	bi *BranchesInfo
+	// new branches which weren't flushed yet
+	newBranches []newBranch
 
 	getEvent func(hash.Event) dag.Event
 
 	callback Callbacks
 
+	// mem keeps the branches data instead of vecDb, if the Engine is reset in memory
+	mem   *memBranches
 	vecDb kvdb.FlushableKVStore
 	table struct {
-		EventBranch  kvdb.Store `table:"b"`
-		BranchesInfo kvdb.Store `table:"B"`
+		EventBranch   kvdb.Store `table:"b"`
+		BranchesInfo  kvdb.Store `table:"B"`
+		BranchFirst   kvdb.Store `table:"f"`
+		ForkObservers kvdb.Store `table:"o"`
 	}
 }
 
@@ -57,6 +65,7 @@ func (vi *Engine) Reset(validators *pos.Validators, db kvdb.Store, getEvent func
 	// use wrapper to be able to drop failed events by dropping cache
 	vi.getEvent = getEvent
 	vi.vecDb = flushable.WrapWithDrop(db, func() {})
+	vi.mem = nil
 	vi.validators = validators
 	vi.validatorIdxs = validators.Idxs()
 	vi.DropNotFlushed()
@@ -67,6 +76,17 @@ func (vi *Engine) Reset(validators *pos.Validators, db kvdb.Store, getEvent func
 	}
 }
 
+// ResetInMemory resets buffers, keeping the branches data in memory instead of a DB.
+// The vectors aren't stored by Engine, so OnDbReset isn't called, and GetSnapshot isn't supported.
+func (vi *Engine) ResetInMemory(validators *pos.Validators, getEvent func(hash.Event) dag.Event) {
+	vi.getEvent = getEvent
+	vi.vecDb = nil
+	vi.mem = newMemBranches()
+	vi.validators = validators
+	vi.validatorIdxs = validators.Idxs()
+	vi.DropNotFlushed()
+}
+
 // Add calculates vector clocks for the event and saves into DB.
 func (vi *Engine) Add(e dag.Event) error {
 	vi.InitBranchesInfo()
@@ -79,15 +99,26 @@ func (vi *Engine) Flush() {
 	if vi.bi != nil {
 		vi.setBranchesInfo(vi.bi)
 	}
-	if err := vi.vecDb.Flush(); err != nil {
+	if vi.mem != nil {
+		vi.mem.Flush()
+	} else if err := vi.vecDb.Flush(); err != nil {
 		vi.crit(err)
 	}
+	vi.notifyNewBranches()
 }
 
 // DropNotFlushed not connected clocks. Call it if event has failed.
 func (vi *Engine) DropNotFlushed() {
 	vi.bi = nil
-	if vi.vecDb.NotFlushedPairs() != 0 {
+	vi.newBranches = nil
+	if vi.mem != nil {
+		if vi.mem.NotFlushed() {
+			vi.mem.DropNotFlushed()
+			if vi.callback.OnDropNotFlushed != nil {
+				vi.callback.OnDropNotFlushed()
+			}
+		}
+	} else if vi.vecDb.NotFlushedPairs() != 0 {
 		vi.vecDb.DropNotFlushed()
 		if vi.callback.OnDropNotFlushed != nil {
 			vi.callback.OnDropNotFlushed()
@@ -116,6 +147,7 @@ func (vi *Engine) fillGlobalBranchID(e dag.Event, meIdx idx.Validator) (idx.Vali
 		if vi.bi.BranchIDLastSeq[meIdx] == 0 {
 			// OK, not a new fork
 			vi.bi.BranchIDLastSeq[meIdx] = e.Seq()
+			vi.setBranchFirstEvent(meIdx, e)
 			return meIdx, nil
 		}
 	} else {
@@ -137,6 +169,12 @@ func (vi *Engine) fillGlobalBranchID(e dag.Event, meIdx idx.Validator) (idx.Vali
 	vi.bi.BranchIDCreatorIdxs = append(vi.bi.BranchIDCreatorIdxs, meIdx)
 	newBranchID := idx.Validator(len(vi.bi.BranchIDLastSeq) - 1)
 	vi.bi.BranchIDByCreators[meIdx] = append(vi.bi.BranchIDByCreators[meIdx], newBranchID)
+	vi.setBranchFirstEvent(newBranchID, e)
+	vi.newBranches = append(vi.newBranches, newBranch{
+		creator:    e.Creator(),
+		branchID:   newBranchID,
+		firstEvent: e.ID(),
+	})
 	return newBranchID, nil
 }
 
@@ -200,6 +238,8 @@ func (vi *Engine) fillEventVectors(e dag.Event) (allVecs, error) {
 					}
 					if myVecs.before.MinSeq(a) <= myVecs.before.Seq(b) && myVecs.before.MinSeq(b) <= myVecs.before.Seq(a) {
 						vi.setForkDetected(myVecs.before, n)
+						// the fork is observed by the event itself, not via its parents
+						vi.addForkObserver(n, e.ID())
 						goto nextCreator
 					}
 				}
@@ -0,0 +1,93 @@
+package vecengine
+
+import (
+	"errors"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/table"
+)
+
+var (
+	ErrNotFlushed = errors.New("vector index has not flushed changes")
+	ErrNoDB       = errors.New("vector index isn't backed by a DB")
+)
+
+// Snapshot is a read-only view of the flushed Engine state.
+// It's safe for concurrent use, and isn't affected by further modifications of Engine.
+// Snapshot must be released after use, by calling Release method.
+type Snapshot struct {
+	crit       func(error)
+	validators *pos.Validators
+	bi         *BranchesInfo
+
+	snap  kvdb.Snapshot
+	table struct {
+		EventBranch kvdb.IteratedReader `table:"b"`
+	}
+}
+
+// GetSnapshot returns a read-only view of the flushed vector clocks.
+// It should be called by the same goroutine which modifies Engine, after Flush.
+// It returns ErrNoDB if Engine keeps the data in memory.
+func (vi *Engine) GetSnapshot() (*Snapshot, error) {
+	if vi.vecDb == nil {
+		return nil, ErrNoDB
+	}
+	if vi.vecDb.NotFlushedPairs() != 0 {
+		return nil, ErrNotFlushed
+	}
+	vi.InitBranchesInfo()
+
+	snap, err := vi.vecDb.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{
+		crit:       vi.crit,
+		validators: vi.validators,
+		bi:         vi.bi.Copy(),
+		snap:       snap,
+	}
+	table.MigrateReaders(&s.table, s.snap)
+	return s, nil
+}
+
+// DB returns the underlying DB snapshot
+func (s *Snapshot) DB() kvdb.IteratedReader {
+	return s.snap
+}
+
+// Validators returns the validators group of the snapshot
+func (s *Snapshot) Validators() *pos.Validators {
+	return s.validators
+}
+
+// BranchesInfo returns global branches of each validator. The result must not be modified.
+func (s *Snapshot) BranchesInfo() *BranchesInfo {
+	return s.bi
+}
+
+func (s *Snapshot) AtLeastOneFork() bool {
+	return idx.Validator(len(s.bi.BranchIDCreatorIdxs)) > s.validators.Len()
+}
+
+// GetEventBranchID reads the event's global branch ID
+func (s *Snapshot) GetEventBranchID(id hash.Event) idx.Validator {
+	b, err := s.table.EventBranch.Get(id.Bytes())
+	if err != nil {
+		s.crit(err)
+	}
+	if b == nil {
+		s.crit(errors.New("failed to read event's branch ID (inconsistent DB)"))
+		return 0
+	}
+	return idx.BytesToValidator(b)
+}
+
+// Release releases the underlying DB snapshot
+func (s *Snapshot) Release() {
+	s.snap.Release()
+}
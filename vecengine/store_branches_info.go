@@ -6,6 +6,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 
 	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/kvdb"
 )
@@ -55,12 +56,19 @@ func (vi *Engine) setBytes(table kvdb.Store, id hash.Event, b []byte) {
 }
 
 func (vi *Engine) setBranchesInfo(info *BranchesInfo) {
+	if vi.mem != nil {
+		vi.mem.SetBranchesInfo(info)
+		return
+	}
 	key := []byte("c")
 
 	vi.setRlp(vi.table.BranchesInfo, key, info)
 }
 
 func (vi *Engine) getBranchesInfo() *BranchesInfo {
+	if vi.mem != nil {
+		return vi.mem.GetBranchesInfo()
+	}
 	key := []byte("c")
 
 	w, exists := vi.getRlp(vi.table.BranchesInfo, key, &BranchesInfo{}).(*BranchesInfo)
@@ -73,11 +81,23 @@ func (vi *Engine) getBranchesInfo() *BranchesInfo {
 
 // SetEventBranchID stores the event's global branch ID
 func (vi *Engine) SetEventBranchID(id hash.Event, branchID idx.Validator) {
+	if vi.mem != nil {
+		vi.mem.SetEventBranchID(id, branchID)
+		return
+	}
 	vi.setBytes(vi.table.EventBranch, id, branchID.Bytes())
 }
 
 // GetEventBranchID reads the event's global branch ID
 func (vi *Engine) GetEventBranchID(id hash.Event) idx.Validator {
+	if vi.mem != nil {
+		branchID, ok := vi.mem.GetEventBranchID(id)
+		if !ok {
+			vi.crit(errors.New("failed to read event's branch ID (inconsistent DB)"))
+			return 0
+		}
+		return branchID
+	}
 	b := vi.getBytes(vi.table.EventBranch, id)
 	if b == nil {
 		vi.crit(errors.New("failed to read event's branch ID (inconsistent DB)"))
@@ -86,3 +106,55 @@ func (vi *Engine) GetEventBranchID(id hash.Event) idx.Validator {
 	branchID := idx.BytesToValidator(b)
 	return branchID
 }
+
+func (vi *Engine) setBranchFirstEvent(branchID idx.Validator, e dag.Event) {
+	if vi.mem != nil {
+		vi.mem.SetBranchFirstEvent(branchID, e.Seq(), e.ID())
+		return
+	}
+	val := append(e.Seq().Bytes(), e.ID().Bytes()...)
+	if err := vi.table.BranchFirst.Put(branchID.Bytes(), val); err != nil {
+		vi.crit(err)
+	}
+}
+
+func (vi *Engine) getBranchFirstEvent(branchID idx.Validator) (idx.Event, hash.Event) {
+	if vi.mem != nil {
+		return vi.mem.GetBranchFirstEvent(branchID)
+	}
+	val, err := vi.table.BranchFirst.Get(branchID.Bytes())
+	if err != nil {
+		vi.crit(err)
+	}
+	if val == nil {
+		return 0, hash.ZeroEvent
+	}
+	return idx.BytesToEvent(val[:4]), hash.BytesToEvent(val[4:])
+}
+
+func (vi *Engine) addForkObserver(creatorIdx idx.Validator, id hash.Event) {
+	if vi.mem != nil {
+		vi.mem.AddForkObserver(creatorIdx, id)
+		return
+	}
+	key := append(creatorIdx.Bytes(), id.Bytes()...)
+	if err := vi.table.ForkObservers.Put(key, []byte{}); err != nil {
+		vi.crit(err)
+	}
+}
+
+func (vi *Engine) getForkObservers(creatorIdx idx.Validator) hash.Events {
+	if vi.mem != nil {
+		return vi.mem.GetForkObservers(creatorIdx)
+	}
+	var res hash.Events
+	it := vi.table.ForkObservers.NewIterator(creatorIdx.Bytes(), nil)
+	defer it.Release()
+	for it.Next() {
+		res = append(res, hash.BytesToEvent(it.Key()[4:]))
+	}
+	if it.Error() != nil {
+		vi.crit(it.Error())
+	}
+	return res
+}
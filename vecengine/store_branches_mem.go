@@ -0,0 +1,141 @@
+package vecengine
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+type branchFirst struct {
+	seq idx.Event
+	id  hash.Event
+}
+
+type memBranchesData struct {
+	eventBranch   map[hash.Event]idx.Validator
+	branchFirst   map[idx.Validator]branchFirst
+	forkObservers map[idx.Validator]hash.EventsSet
+	bi            *BranchesInfo
+}
+
+func newMemBranchesData() memBranchesData {
+	return memBranchesData{
+		eventBranch:   make(map[hash.Event]idx.Validator),
+		branchFirst:   make(map[idx.Validator]branchFirst),
+		forkObservers: make(map[idx.Validator]hash.EventsSet),
+	}
+}
+
+func (d *memBranchesData) empty() bool {
+	return len(d.eventBranch) == 0 && len(d.branchFirst) == 0 && len(d.forkObservers) == 0 && d.bi == nil
+}
+
+// memBranches keeps the branches data in Go maps, instead of a key-value DB.
+// The changes are kept apart until flushed, the same as in the flushable DB.
+type memBranches struct {
+	flushed    memBranchesData
+	notFlushed memBranchesData
+}
+
+func newMemBranches() *memBranches {
+	return &memBranches{
+		flushed:    newMemBranchesData(),
+		notFlushed: newMemBranchesData(),
+	}
+}
+
+func (m *memBranches) NotFlushed() bool {
+	return !m.notFlushed.empty()
+}
+
+func (m *memBranches) Flush() {
+	for id, branchID := range m.notFlushed.eventBranch {
+		m.flushed.eventBranch[id] = branchID
+	}
+	for branchID, first := range m.notFlushed.branchFirst {
+		m.flushed.branchFirst[branchID] = first
+	}
+	for creatorIdx, observers := range m.notFlushed.forkObservers {
+		if m.flushed.forkObservers[creatorIdx] == nil {
+			m.flushed.forkObservers[creatorIdx] = hash.EventsSet{}
+		}
+		for id := range observers {
+			m.flushed.forkObservers[creatorIdx].Add(id)
+		}
+	}
+	if m.notFlushed.bi != nil {
+		m.flushed.bi = m.notFlushed.bi
+	}
+	m.DropNotFlushed()
+}
+
+func (m *memBranches) DropNotFlushed() {
+	m.notFlushed = newMemBranchesData()
+}
+
+func (m *memBranches) SetBranchesInfo(info *BranchesInfo) {
+	// the info is modified in place by Engine, so a copy is stored
+	m.notFlushed.bi = info.Copy()
+}
+
+func (m *memBranches) GetBranchesInfo() *BranchesInfo {
+	if m.notFlushed.bi != nil {
+		return m.notFlushed.bi.Copy()
+	}
+	if m.flushed.bi != nil {
+		return m.flushed.bi.Copy()
+	}
+	return nil
+}
+
+func (m *memBranches) SetEventBranchID(id hash.Event, branchID idx.Validator) {
+	m.notFlushed.eventBranch[id] = branchID
+}
+
+func (m *memBranches) GetEventBranchID(id hash.Event) (idx.Validator, bool) {
+	if branchID, ok := m.notFlushed.eventBranch[id]; ok {
+		return branchID, true
+	}
+	branchID, ok := m.flushed.eventBranch[id]
+	return branchID, ok
+}
+
+func (m *memBranches) SetBranchFirstEvent(branchID idx.Validator, seq idx.Event, id hash.Event) {
+	m.notFlushed.branchFirst[branchID] = branchFirst{seq, id}
+}
+
+func (m *memBranches) GetBranchFirstEvent(branchID idx.Validator) (idx.Event, hash.Event) {
+	if first, ok := m.notFlushed.branchFirst[branchID]; ok {
+		return first.seq, first.id
+	}
+	first := m.flushed.branchFirst[branchID]
+	return first.seq, first.id
+}
+
+func (m *memBranches) AddForkObserver(creatorIdx idx.Validator, id hash.Event) {
+	if m.notFlushed.forkObservers[creatorIdx] == nil {
+		m.notFlushed.forkObservers[creatorIdx] = hash.EventsSet{}
+	}
+	m.notFlushed.forkObservers[creatorIdx].Add(id)
+}
+
+// GetForkObservers returns the observers ordered by ID, the same as they are iterated in the DB
+func (m *memBranches) GetForkObservers(creatorIdx idx.Validator) hash.Events {
+	set := hash.EventsSet{}
+	for id := range m.flushed.forkObservers[creatorIdx] {
+		set.Add(id)
+	}
+	for id := range m.notFlushed.forkObservers[creatorIdx] {
+		set.Add(id)
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	res := set.Slice()
+	sort.Slice(res, func(i, j int) bool {
+		return bytes.Compare(res[i].Bytes(), res[j].Bytes()) < 0
+	})
+	return res
+}
@@ -0,0 +1,192 @@
+package vecfc
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+)
+
+// TestIndexer is the part of the index API which is covered by the shared test suite.
+// Every vector index backend is tested through it.
+type TestIndexer interface {
+	Add(dag.Event) error
+	Flush()
+	DropNotFlushed()
+	Reset(validators *pos.Validators, db kvdb.Store, getEvent func(hash.Event) dag.Event)
+	ForklessCause(aID, bID hash.Event) bool
+	DfsSubgraph(head dag.Event, walk func(hash.Event) (godeeper bool)) error
+
+	// HighestBefore returns nil if vector isn't found
+	HighestBefore(id hash.Event) HighestBeforeReader
+	// LowestAfter returns nil if vector isn't found
+	LowestAfter(id hash.Event) LowestAfterReader
+	MergedHighestBefore(id hash.Event) []BranchSeq
+	PurgeCaches()
+}
+
+type testBackend struct {
+	name string
+	new  func() TestIndexer
+}
+
+var testBackends = []testBackend{
+	{
+		name: "vecfc",
+		new: func() TestIndexer {
+			return fcIndexer{NewIndex(tCrit, LiteConfig())}
+		},
+	},
+}
+
+// RegisterTestBackend adds an index backend to the shared test suite.
+// It's called by the external test package, which may import other backends without an import cycle.
+func RegisterTestBackend(name string, new func() TestIndexer) {
+	testBackends = append(testBackends, testBackend{name, new})
+}
+
+type fcIndexer struct {
+	*Index
+}
+
+func (vi fcIndexer) HighestBefore(id hash.Event) HighestBeforeReader {
+	if v := vi.GetHighestBefore(id); v != nil {
+		return v
+	}
+	return nil
+}
+
+func (vi fcIndexer) LowestAfter(id hash.Event) LowestAfterReader {
+	if v := vi.GetLowestAfter(id); v != nil {
+		return v
+	}
+	return nil
+}
+
+func (vi fcIndexer) MergedHighestBefore(id hash.Event) []BranchSeq {
+	v := vi.GetMergedHighestBefore(id)
+	res := make([]BranchSeq, v.Size())
+	for i := range res {
+		res[i] = v.Get(idx.Validator(i))
+	}
+	return res
+}
+
+func (vi fcIndexer) PurgeCaches() {
+	vi.cache.ForklessCause.Purge()
+}
+
+type testResults struct {
+	forklessCause map[[2]hash.Event]bool
+	merged        map[hash.Event][]BranchSeq
+}
+
+// testIndexerResults processes events (dropping every second event before re-adding it) and collects the index answers
+func testIndexerResults(vi TestIndexer, validators *pos.Validators, ordered dag.Events) testResults {
+	processed := make(map[hash.Event]dag.Event)
+	getEvent := func(id hash.Event) dag.Event {
+		return processed[id]
+	}
+	vi.Reset(validators, memorydb.New(), getEvent)
+
+	for i, e := range ordered {
+		processed[e.ID()] = e
+		if i%2 == 0 {
+			if err := vi.Add(e); err != nil {
+				panic(err)
+			}
+			vi.DropNotFlushed()
+		}
+		if err := vi.Add(e); err != nil {
+			panic(err)
+		}
+		vi.Flush()
+	}
+
+	res := testResults{
+		forklessCause: make(map[[2]hash.Event]bool),
+		merged:        make(map[hash.Event][]BranchSeq),
+	}
+	for _, a := range ordered {
+		res.merged[a.ID()] = vi.MergedHighestBefore(a.ID())
+		for _, b := range ordered {
+			res.forklessCause[[2]hash.Event{a.ID(), b.ID()}] = vi.ForklessCause(a.ID(), b.ID())
+		}
+	}
+	return res
+}
+
+func TestBackendsSameResults(t *testing.T) {
+	for i, test := range []struct {
+		nodesNum    int
+		cheatersNum int
+		eventsNum   int
+		forksNum    int
+		parentsNum  int
+	}{
+		{
+			nodesNum:    1,
+			cheatersNum: 1,
+			eventsNum:   10,
+			forksNum:    3,
+			parentsNum:  1,
+		},
+		{
+			nodesNum:    5,
+			cheatersNum: 0,
+			eventsNum:   20,
+			forksNum:    0,
+			parentsNum:  3,
+		},
+		{
+			nodesNum:    10,
+			cheatersNum: 3,
+			eventsNum:   10,
+			forksNum:    3,
+			parentsNum:  4,
+		},
+		{
+			nodesNum:    5,
+			cheatersNum: 2,
+			eventsNum:   30,
+			forksNum:    30,
+			parentsNum:  4,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test #%d", i), func(t *testing.T) {
+			r := rand.New(rand.NewSource(int64(i)))
+
+			nodes := tdag.GenNodes(test.nodesNum)
+			cheaters := nodes[:test.cheatersNum]
+			validators := pos.EqualWeightValidators(nodes, 1)
+
+			var ordered dag.Events
+			seen := hash.EventsSet{}
+			_ = tdag.ForEachRandFork(nodes, cheaters, test.eventsNum, test.parentsNum, test.forksNum, r, tdag.ForEachEvent{
+				Process: func(e dag.Event, name string) {
+					if seen.Contains(e.ID()) {
+						return
+					}
+					seen.Add(e.ID())
+					ordered = append(ordered, e)
+				},
+			})
+
+			expected := testIndexerResults(testBackends[0].new(), validators, ordered)
+			for _, backend := range testBackends[1:] {
+				got := testIndexerResults(backend.new(), validators, ordered)
+				assert.Equal(t, expected.merged, got.merged, backend.name)
+				assert.Equal(t, expected.forklessCause, got.forklessCause, backend.name)
+			}
+		})
+	}
+}
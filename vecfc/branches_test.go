@@ -0,0 +1,93 @@
+package vecfc
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+)
+
+func TestIndex_OnNewBranch(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(6)
+	cheaters := nodes[:2]
+	validators := pos.EqualWeightValidators(nodes, 1)
+
+	processed := make(map[hash.Event]dag.Event)
+	getEvent := func(id hash.Event) dag.Event {
+		return processed[id]
+	}
+
+	vi := NewIndex(tCrit, LiteConfig())
+	vi.Reset(validators, memorydb.New(), getEvent)
+
+	type notification struct {
+		creator    idx.ValidatorID
+		branchID   idx.Validator
+		firstEvent hash.Event
+	}
+	var notified []notification
+	vi.SetOnNewBranch(func(creator idx.ValidatorID, branchID idx.Validator, firstEvent hash.Event) {
+		notified = append(notified, notification{creator, branchID, firstEvent})
+	})
+
+	_ = tdag.ForEachRandFork(nodes, cheaters, 20, 3, 3, rand.New(rand.NewSource(0)), tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			if _, ok := processed[e.ID()]; ok {
+				return
+			}
+			processed[e.ID()] = e
+
+			// not flushed branches aren't notified
+			notifiedBefore := len(notified)
+			require.NoError(vi.Add(e))
+			vi.DropNotFlushed()
+			require.Equal(notifiedBefore, len(notified))
+
+			require.NoError(vi.Add(e))
+			vi.Flush()
+		},
+	})
+
+	require.True(vi.AtLeastOneFork())
+	forkBranches := len(vi.BranchesInfo().BranchIDCreatorIdxs) - int(validators.Len())
+	require.Equal(forkBranches, len(notified))
+
+	for _, n := range notified {
+		require.Contains(cheaters, n.creator)
+		require.GreaterOrEqual(n.branchID, validators.Len())
+		require.Equal(n.creator, processed[n.firstEvent].Creator())
+	}
+
+	all := vi.GetAllBranches()
+	require.Equal(int(validators.Len()), len(all))
+	for _, cb := range all {
+		isCheater := cb.Creator == cheaters[0] || cb.Creator == cheaters[1]
+		require.Equal(isCheater, len(cb.Branches) > 1, cb.Creator)
+		require.Equal(isCheater, len(cb.ForkObservers) > 0, cb.Creator)
+		for _, b := range cb.Branches {
+			first := processed[b.FirstEvent]
+			require.NotNil(first)
+			require.Equal(cb.Creator, first.Creator())
+			require.Equal(first.Seq(), b.FirstSeq)
+			require.GreaterOrEqual(b.LastSeq, b.FirstSeq)
+		}
+		for _, id := range cb.ForkObservers {
+			require.True(vi.GetMergedHighestBefore(id).Get(validators.GetIdx(cb.Creator)).IsForkDetected())
+		}
+	}
+	cb, ok := vi.GetCreatorBranches(cheaters[0])
+	require.True(ok)
+	require.Equal(all[validators.GetIdx(cheaters[0])], cb)
+
+	_, ok = vi.GetCreatorBranches(idx.ValidatorID(1000))
+	require.False(ok)
+}
@@ -0,0 +1,173 @@
+package vecfc
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/utils/simplewlru"
+)
+
+const (
+	// growMissRate is a miss rate of a full cache, above which the cache is grown
+	growMissRate = 0.1
+	// shrinkUsage is a part of the cache size, below which the underused cache is shrunk, if the miss rate is low
+	shrinkUsage = 0.25
+)
+
+// CacheStats is a number of cache lookups
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// IndexCacheStats - cache lookups statistics of Index
+type IndexCacheStats struct {
+	ForklessCause    CacheStats
+	HighestBeforeSeq CacheStats
+	LowestAfterSeq   CacheStats
+}
+
+// HitRate returns a ratio of hits to all the lookups, or 0 if there were no lookups
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// statCache is simplewlru.Cache which counts the lookups and resizes itself within [min, max] size,
+// depending on the miss rate during the last period of lookups.
+// Cache size is both the max weight and the max number of items.
+type statCache struct {
+	*simplewlru.Cache
+
+	size     uint
+	min, max uint
+	period   uint64
+
+	stats  CacheStats
+	window CacheStats
+}
+
+func newStatCache(size, min, max uint, period int) *statCache {
+	if min == 0 || min > size {
+		min = size
+	}
+	if max < size {
+		max = size
+	}
+	c := &statCache{
+		size:   size,
+		min:    min,
+		max:    max,
+		period: uint64(period),
+	}
+	c.Cache, _ = simplewlru.New(size, int(size))
+	return c
+}
+
+// Get looks up a key's value from the cache, and counts the lookup.
+func (c *statCache) Get(key interface{}) (value interface{}, ok bool) {
+	value, ok = c.Cache.Get(key)
+	if ok {
+		c.stats.Hits++
+		c.window.Hits++
+	} else {
+		c.stats.Misses++
+		c.window.Misses++
+	}
+	if c.period != 0 && c.window.Hits+c.window.Misses >= c.period {
+		c.adapt()
+	}
+	return value, ok
+}
+
+// Full returns true if the cache reached its current size.
+func (c *statCache) Full() bool {
+	weight, num := c.Total()
+	return weight >= c.size || uint(num) >= c.size
+}
+
+func (c *statCache) adapt() {
+	missRate := 1 - c.window.HitRate()
+	weight, _ := c.Total()
+	c.window = CacheStats{}
+
+	size := c.size
+	if missRate > growMissRate && c.Full() {
+		size *= 2
+		if size > c.max {
+			size = c.max
+		}
+	} else if missRate <= growMissRate && float64(weight) < float64(c.size)*shrinkUsage {
+		size /= 2
+		if size < c.min {
+			size = c.min
+		}
+	}
+	if size != c.size {
+		c.size = size
+		c.Resize(size, int(size))
+	}
+}
+
+// Warmup loads the vectors of the given events and their ancestors into the free space of the caches.
+// Recent events should go first, e.g. roots of the last frames, they will be the last to be evicted.
+// Lookups made by Warmup aren't counted in the statistics.
+func (vi *Index) Warmup(ids hash.Events) {
+	type warm struct {
+		id hash.Event
+		hb *HighestBeforeSeq
+		la *LowestAfterSeq
+	}
+	hbFree := int(vi.cache.HighestBeforeSeq.size) - int(vi.cache.HighestBeforeSeq.Weight())
+	laFree := int(vi.cache.LowestAfterSeq.size) - int(vi.cache.LowestAfterSeq.Weight())
+	// a vector has at least one element per validator, so no more vectors may fit into the caches
+	maxWalk := len(ids)
+	if n := int(vi.validators.Len()); n != 0 {
+		maxWalk += hbFree/(8*n) + laFree/(4*n)
+	}
+
+	var loaded []warm
+	visited := hash.EventsSet{}
+	queue := append(make(hash.Events, 0, len(ids)), ids...)
+	for len(queue) > 0 && len(visited) < maxWalk {
+		id := queue[0]
+		queue = queue[1:]
+		if visited.Contains(id) {
+			continue
+		}
+		visited.Add(id)
+
+		w := warm{id: id}
+		hb := HighestBeforeSeq(vi.getBytes(vi.table.HighestBeforeSeq, id))
+		la := LowestAfterSeq(vi.getBytes(vi.table.LowestAfterSeq, id))
+		if (hb != nil || la != nil) && len(hb) > hbFree && len(la) > laFree {
+			// neither cache can accept the vectors, the older ones won't fit either
+			break
+		}
+		if hb != nil && len(hb) <= hbFree {
+			hbFree -= len(hb)
+			w.hb = &hb
+		}
+		if la != nil && len(la) <= laFree {
+			laFree -= len(la)
+			w.la = &la
+		}
+		loaded = append(loaded, w)
+
+		if e := vi.getEvent(id); e != nil {
+			queue = append(queue, e.Parents()...)
+		}
+	}
+
+	// add the oldest events first, so the recent ones would be evicted last
+	for i := len(loaded) - 1; i >= 0; i-- {
+		w := loaded[i]
+		if w.hb != nil && !vi.cache.HighestBeforeSeq.Contains(w.id) {
+			vi.cache.HighestBeforeSeq.Add(w.id, w.hb, uint(len(*w.hb)))
+		}
+		if w.la != nil && !vi.cache.LowestAfterSeq.Contains(w.id) {
+			vi.cache.LowestAfterSeq.Add(w.id, w.la, uint(len(*w.la)))
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package vecfc
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
+)
+
+func testCachesEvents(nodesNum, eventsNum int) (*pos.Validators, dag.Events, func(hash.Event) dag.Event) {
+	nodes := tdag.GenNodes(nodesNum)
+	validators := pos.EqualWeightValidators(nodes, 1)
+
+	var ordered dag.Events
+	events := make(map[hash.Event]dag.Event)
+	_ = tdag.ForEachRandEvent(nodes, eventsNum, 3, rand.New(rand.NewSource(0)), tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+			events[e.ID()] = e
+		},
+	})
+	return validators, ordered, func(id hash.Event) dag.Event {
+		return events[id]
+	}
+}
+
+func TestIndex_CachesResize(t *testing.T) {
+	require := require.New(t)
+
+	validators, ordered, getEvent := testCachesEvents(10, 30)
+
+	cfg := AdaptiveConfig(cachescale.Ratio{Base: 100, Target: 1})
+	cfg.CachesResizePeriod = 100
+	vi := NewIndex(tCrit, cfg)
+	vi.Reset(validators, memorydb.New(), getEvent)
+	for _, e := range ordered {
+		require.NoError(vi.Add(e))
+		vi.Flush()
+	}
+	require.Equal(cfg.Caches, vi.CacheSizes())
+
+	// working set is larger than the caches, so they grow up to the max size
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 100000; i++ {
+		a := ordered[r.Intn(len(ordered))]
+		b := ordered[r.Intn(len(ordered))]
+		vi.ForklessCause(a.ID(), b.ID())
+	}
+	require.Equal(cfg.MaxCaches.ForklessCausePairs, vi.CacheSizes().ForklessCausePairs)
+	require.LessOrEqual(vi.CacheSizes().HighestBeforeSeqSize, cfg.MaxCaches.HighestBeforeSeqSize)
+	require.LessOrEqual(vi.cache.ForklessCause.Len(), cfg.MaxCaches.ForklessCausePairs)
+	stats := vi.CacheStats()
+	require.NotZero(stats.ForklessCause.Hits)
+	require.NotZero(stats.ForklessCause.Misses)
+	require.NotZero(stats.HighestBeforeSeq.Hits + stats.HighestBeforeSeq.Misses)
+
+	// working set is much smaller than the purged caches, so they shrink down to the min size
+	vi.Reset(validators, memorydb.New(), getEvent)
+	for _, e := range ordered[:2] {
+		require.NoError(vi.Add(e))
+		vi.Flush()
+	}
+	for i := 0; i < 100000; i++ {
+		vi.ForklessCause(ordered[i%2].ID(), ordered[0].ID())
+		vi.GetHighestBefore(ordered[i%2].ID())
+		vi.GetLowestAfter(ordered[i%2].ID())
+	}
+	require.Equal(cfg.MinCaches, vi.CacheSizes())
+	require.Greater(vi.CacheStats().ForklessCause.HitRate(), stats.ForklessCause.HitRate())
+}
+
+func TestIndex_Warmup(t *testing.T) {
+	require := require.New(t)
+
+	validators, ordered, getEvent := testCachesEvents(5, 10)
+
+	db := memorydb.New()
+	vi := NewIndex(tCrit, LiteConfig())
+	vi.Reset(validators, db, getEvent)
+	for _, e := range ordered {
+		require.NoError(vi.Add(e))
+		vi.Flush()
+	}
+
+	// restart
+	vi = NewIndex(tCrit, LiteConfig())
+	vi.Reset(validators, db, getEvent)
+	head := ordered[len(ordered)-1]
+	vi.Warmup(hash.Events{head.ID()})
+	require.Zero(vi.CacheStats().HighestBeforeSeq.Hits + vi.CacheStats().HighestBeforeSeq.Misses)
+
+	// the head and its recent ancestors are in the caches
+	require.NotNil(vi.GetHighestBefore(head.ID()))
+	require.NotNil(vi.GetLowestAfter(head.ID()))
+	for _, p := range head.Parents() {
+		require.NotNil(vi.GetHighestBefore(p))
+		require.NotNil(vi.GetLowestAfter(p))
+	}
+	stats := vi.CacheStats()
+	require.Zero(stats.HighestBeforeSeq.Misses)
+	require.Zero(stats.LowestAfterSeq.Misses)
+	require.Equal(uint64(len(head.Parents())+1), stats.HighestBeforeSeq.Hits)
+	require.Equal(1.0, stats.HighestBeforeSeq.HitRate())
+}
+
+func TestIndex_CachesNotAdaptiveByDefault(t *testing.T) {
+	require := require.New(t)
+
+	validators, ordered, getEvent := testCachesEvents(10, 30)
+
+	cfg := LiteConfig()
+	require.Zero(cfg.CachesResizePeriod)
+	vi := NewIndex(tCrit, cfg)
+	vi.Reset(validators, memorydb.New(), getEvent)
+	for _, e := range ordered {
+		require.NoError(vi.Add(e))
+		vi.Flush()
+	}
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 100000; i++ {
+		a := ordered[r.Intn(len(ordered))]
+		b := ordered[r.Intn(len(ordered))]
+		vi.ForklessCause(a.ID(), b.ID())
+	}
+	require.Equal(cfg.Caches, vi.CacheSizes())
+}
+
+func TestIndex_WarmupStopsWhenFull(t *testing.T) {
+	require := require.New(t)
+
+	validators, ordered, getEvent := testCachesEvents(5, 30)
+
+	db := memorydb.New()
+	vi := NewIndex(tCrit, LiteConfig())
+	vi.Reset(validators, db, getEvent)
+	for _, e := range ordered {
+		require.NoError(vi.Add(e))
+		vi.Flush()
+	}
+
+	// caches fit a vector and a half, i.e. there's always a bit of free space left
+	cfg := LiteConfig()
+	cfg.Caches.HighestBeforeSeqSize = 8 * uint(validators.Len()) * 3 / 2
+	cfg.Caches.LowestAfterSeqSize = 4 * uint(validators.Len()) * 3 / 2
+	walked := 0
+	countingGetEvent := func(id hash.Event) dag.Event {
+		walked++
+		return getEvent(id)
+	}
+	vi = NewIndex(tCrit, cfg)
+	vi.Reset(validators, db, countingGetEvent)
+	head := ordered[len(ordered)-1]
+	vi.Warmup(hash.Events{head.ID()})
+
+	// only the head fits, the walk stops at its parents
+	require.Equal(1, walked)
+	require.Equal(1, vi.cache.HighestBeforeSeq.Len())
+	require.Equal(1, vi.cache.LowestAfterSeq.Len())
+	require.True(vi.cache.HighestBeforeSeq.Contains(head.ID()))
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/vecengine"
 )
 
 type kv struct {
@@ -60,9 +62,25 @@ func (vi *Index) forklessCause(aID, bID hash.Event) bool {
 		return false
 	}
 
-	yes := vi.validators.NewCounter()
+	return ForklessCauseByVectors(vi.validators, vi.Engine.BranchesInfo(), a, b)
+}
+
+// HighestBeforeReader is a read-only HighestBefore vector clock of any index backend
+type HighestBeforeReader interface {
+	Get(i idx.Validator) BranchSeq
+}
+
+// LowestAfterReader is a read-only LowestAfter vector clock of any index backend
+type LowestAfterReader interface {
+	Get(i idx.Validator) idx.Event
+}
+
+// ForklessCauseByVectors checks that A observes that {QUORUM} non-cheater-validators observe B.
+// A fork of B must be checked by the caller beforehand.
+func ForklessCauseByVectors(validators *pos.Validators, bi *vecengine.BranchesInfo, a HighestBeforeReader, b LowestAfterReader) bool {
+	yes := validators.NewCounter()
 	// calculate forkless causing using the indexes
-	branchIDs := vi.Engine.BranchesInfo().BranchIDCreatorIdxs
+	branchIDs := bi.BranchIDCreatorIdxs
 	for branchIDint, creatorIdx := range branchIDs {
 		branchID := idx.Validator(branchIDint)
 
@@ -126,6 +126,14 @@ a1_5 ────╣        ║
 //  "<name>_<level>[(by-level)]",
 // where by-level means that event is forkless seen by all event with level >= by-level.
 func testForklessCaused(t *testing.T, dagAscii string) {
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			testForklessCausedBy(t, backend.new(), dagAscii)
+		})
+	}
+}
+
+func testForklessCausedBy(t *testing.T, vi TestIndexer, dagAscii string) {
 	assertar := assert.New(t)
 
 	nodes, _, _ := tdag.ASCIIschemeToDAG(dagAscii)
@@ -136,7 +144,6 @@ func testForklessCaused(t *testing.T, dagAscii string) {
 		return events[id]
 	}
 
-	vi := NewIndex(tCrit, LiteConfig())
 	vi.Reset(validators, memorydb.New(), getEvent)
 
 	_, _, named := tdag.ASCIIschemeForEach(dagAscii, tdag.ForEachEvent{
@@ -193,8 +200,6 @@ func decode(dsc string) (level, bylevel int64) {
 }
 
 func TestForklessCausedRandom(t *testing.T) {
-	assertar := assert.New(t)
-
 	// generated by codegen4ForklessCausedStability()
 	dagAscii := `
  a000    
@@ -454,31 +459,37 @@ func TestForklessCausedRandom(t *testing.T) {
 		return events[id]
 	}
 
-	vi := NewIndex(tCrit, LiteConfig())
-	vi.Reset(validators, memorydb.New(), getEvent)
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			assertar := assert.New(t)
 
-	// push
-	for _, e := range ordered {
-		events[e.ID()] = e
-		err := vi.Add(e)
-		if err != nil {
-			panic(err)
-		}
-		vi.Flush()
-	}
+			vi := backend.new()
+			vi.Reset(validators, memorydb.New(), getEvent)
 
-	// check
-	for e1name, e1 := range named {
-		for e2name, e2 := range named {
-			_, expect := relations[e1name][e2name]
-			if !assertar.Equal(
-				expect,
-				vi.ForklessCause(e1.ID(), e2.ID()),
-				fmt.Sprintf("%s forkless sees %s", e1.ID(), e2.ID()),
-			) {
-				return
+			// push
+			for _, e := range ordered {
+				events[e.ID()] = e
+				err := vi.Add(e)
+				if err != nil {
+					panic(err)
+				}
+				vi.Flush()
 			}
-		}
+
+			// check
+			for e1name, e1 := range named {
+				for e2name, e2 := range named {
+					_, expect := relations[e1name][e2name]
+					if !assertar.Equal(
+						expect,
+						vi.ForklessCause(e1.ID(), e2.ID()),
+						fmt.Sprintf("%s forkless sees %s", e1.ID(), e2.ID()),
+					) {
+						return
+					}
+				}
+			}
+		})
 	}
 }
 
@@ -488,7 +499,7 @@ type eventSlot struct {
 }
 
 // naive implementation of fork detection, O(n)
-func testForksDetected(vi *Index, head dag.Event) (cheaters map[idx.ValidatorID]bool, err error) {
+func testForksDetected(vi TestIndexer, getEvent func(hash.Event) dag.Event, head dag.Event) (cheaters map[idx.ValidatorID]bool, err error) {
 	cheaters = map[idx.ValidatorID]bool{}
 	visited := hash.EventsSet{}
 	detected := map[eventSlot]int{}
@@ -499,7 +510,7 @@ func testForksDetected(vi *Index, head dag.Event) (cheaters map[idx.ValidatorID]
 		}
 		visited.Add(id)
 
-		e := vi.getEvent(id)
+		e := getEvent(id)
 		slot := eventSlot{
 			seq:     e.Seq(),
 			creator: e.Creator(),
@@ -531,47 +542,51 @@ func TestRandomForksSanity(t *testing.T) {
 	validatorsBuilder.Set(nodes[4], pos.Weight(3))
 	validators := validatorsBuilder.Build()
 
-	processed := make(map[hash.Event]dag.Event)
-	getEvent := func(id hash.Event) dag.Event {
-		return processed[id]
-	}
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			processed := make(map[hash.Event]dag.Event)
+			getEvent := func(id hash.Event) dag.Event {
+				return processed[id]
+			}
 
-	vi := NewIndex(tCrit, LiteConfig())
-	vi.Reset(validators, memorydb.New(), getEvent)
+			vi := backend.new()
+			vi.Reset(validators, memorydb.New(), getEvent)
 
-	// Many forks from each node in large graph, so probability of not seeing a fork is negligible
-	events := tdag.ForEachRandFork(nodes, cheaters, 300, 4, 30, nil, tdag.ForEachEvent{
-		Process: func(e dag.Event, name string) {
-			if _, ok := processed[e.ID()]; ok {
-				return
-			}
-			processed[e.ID()] = e
-			err := vi.Add(e)
-			if err != nil {
-				panic(err)
-			}
-		},
-	})
+			// Many forks from each node in large graph, so probability of not seeing a fork is negligible
+			events := tdag.ForEachRandFork(nodes, cheaters, 300, 4, 30, nil, tdag.ForEachEvent{
+				Process: func(e dag.Event, name string) {
+					if _, ok := processed[e.ID()]; ok {
+						return
+					}
+					processed[e.ID()] = e
+					err := vi.Add(e)
+					if err != nil {
+						panic(err)
+					}
+				},
+			})
 
-	vi.Flush()
-	vi.DropNotFlushed() // doesn't drop anything, because everything is flushed
+			vi.Flush()
+			vi.DropNotFlushed() // doesn't drop anything, because everything is flushed
 
-	// quick sanity check. all the nodes should see that cheaters have a fork, and honest nodes don't have forks
-	assertar := assert.New(t)
-	idxs := validatorsBuilder.Build().Idxs()
-	for _, node := range nodes {
-		ee := events[node]
-		highestBefore := vi.GetMergedHighestBefore(ee[len(ee)-1].ID())
-		for n, cheater := range nodes {
-			branchSeq := highestBefore.Get(idxs[cheater])
-			isCheater := n < len(cheaters)
-			assertar.Equal(isCheater, branchSeq.IsForkDetected(), cheater)
-			if isCheater {
-				assertar.Equal(idx.Event(0), branchSeq.Seq, cheater)
-			} else {
-				assertar.NotEqual(idx.Event(0), branchSeq.Seq, cheater)
+			// quick sanity check. all the nodes should see that cheaters have a fork, and honest nodes don't have forks
+			assertar := assert.New(t)
+			idxs := validatorsBuilder.Build().Idxs()
+			for _, node := range nodes {
+				ee := events[node]
+				highestBefore := vi.MergedHighestBefore(ee[len(ee)-1].ID())
+				for n, cheater := range nodes {
+					branchSeq := highestBefore[idxs[cheater]]
+					isCheater := n < len(cheaters)
+					assertar.Equal(isCheater, branchSeq.IsForkDetected(), cheater)
+					if isCheater {
+						assertar.Equal(idx.Event(0), branchSeq.Seq, cheater)
+					} else {
+						assertar.NotEqual(idx.Event(0), branchSeq.Seq, cheater)
+					}
+				}
 			}
-		}
+		})
 	}
 }
 
@@ -649,100 +664,102 @@ func TestRandomForks(t *testing.T) {
 			reorderChecks: 2,
 		},
 	} {
-		t.Run(fmt.Sprintf("Test #%d", i), func(t *testing.T) {
-			r := rand.New(rand.NewSource(int64(i)))
+		for _, backend := range testBackends {
+			t.Run(fmt.Sprintf("%s/Test #%d", backend.name, i), func(t *testing.T) {
+				r := rand.New(rand.NewSource(int64(i)))
 
-			nodes := tdag.GenNodes(test.nodesNum)
-			cheaters := nodes[:test.cheatersNum]
+				nodes := tdag.GenNodes(test.nodesNum)
+				cheaters := nodes[:test.cheatersNum]
 
-			validators := pos.EqualWeightValidators(nodes, 1)
-
-			processedArr := dag.Events{}
-			processed := make(map[hash.Event]dag.Event)
-			getEvent := func(id hash.Event) dag.Event {
-				return processed[id]
-			}
+				validators := pos.EqualWeightValidators(nodes, 1)
 
-			vi := NewIndex(tCrit, LiteConfig())
-			vi.Reset(validators, memorydb.New(), getEvent)
+				processedArr := dag.Events{}
+				processed := make(map[hash.Event]dag.Event)
+				getEvent := func(id hash.Event) dag.Event {
+					return processed[id]
+				}
 
-			_ = tdag.ForEachRandFork(nodes, cheaters, test.eventsNum, test.parentsNum, test.forksNum, r, tdag.ForEachEvent{
-				Process: func(e dag.Event, name string) {
-					if _, ok := processed[e.ID()]; ok {
-						return
-					}
-					processed[e.ID()] = e
-					processedArr = append(processedArr, e)
-					err := vi.Add(e)
-					if err != nil {
-						panic(err)
-					}
-				},
-			})
+				vi := backend.new()
+				vi.Reset(validators, memorydb.New(), getEvent)
 
-			assertar := assert.New(t)
-			idxs := validators.Idxs()
-			// check that fork observing is identical to naive version
-			for _, e := range processed {
-				highestBefore := vi.GetHighestBefore(e.ID())
-				expectedCheaters, err := testForksDetected(vi, e)
-				assertar.NoError(err)
-
-				for _, cheater := range nodes {
-					expectedCheater := expectedCheaters[cheater]
-					branchSeq := highestBefore.Get(idxs[cheater])
-					assertar.Equal(expectedCheater, branchSeq.IsForkDetected(), e.String())
-					if expectedCheater {
-						assertar.Equal(idx.Event(0), branchSeq.Seq, e.String())
+				_ = tdag.ForEachRandFork(nodes, cheaters, test.eventsNum, test.parentsNum, test.forksNum, r, tdag.ForEachEvent{
+					Process: func(e dag.Event, name string) {
+						if _, ok := processed[e.ID()]; ok {
+							return
+						}
+						processed[e.ID()] = e
+						processedArr = append(processedArr, e)
+						err := vi.Add(e)
+						if err != nil {
+							panic(err)
+						}
+					},
+				})
+
+				assertar := assert.New(t)
+				idxs := validators.Idxs()
+				// check that fork observing is identical to naive version
+				for _, e := range processed {
+					highestBefore := vi.HighestBefore(e.ID())
+					expectedCheaters, err := testForksDetected(vi, getEvent, e)
+					assertar.NoError(err)
+
+					for _, cheater := range nodes {
+						expectedCheater := expectedCheaters[cheater]
+						branchSeq := highestBefore.Get(idxs[cheater])
+						assertar.Equal(expectedCheater, branchSeq.IsForkDetected(), e.String())
+						if expectedCheater {
+							assertar.Equal(idx.Event(0), branchSeq.Seq, e.String())
+						}
 					}
 				}
-			}
 
-			// memorize results of ForklessCause and MedianTime
-			forklessCauseMap := map[kv]bool{}
-			for _, a := range processedArr {
-				for _, b := range processedArr {
-					pair := kv{
-						a: a.ID(),
-						b: b.ID(),
+				// memorize results of ForklessCause and MedianTime
+				forklessCauseMap := map[kv]bool{}
+				for _, a := range processedArr {
+					for _, b := range processedArr {
+						pair := kv{
+							a: a.ID(),
+							b: b.ID(),
+						}
+						forklessCauseMap[pair] = vi.ForklessCause(a.ID(), b.ID())
 					}
-					forklessCauseMap[pair] = vi.ForklessCause(a.ID(), b.ID())
 				}
-			}
 
-			vi.DropNotFlushed() // drops everything, because wasn't flushed
-			for _, e := range processed {
-				assertar.Nil(vi.GetHighestBefore(e.ID()))
-				assertar.Nil(vi.GetLowestAfter(e.ID()))
-			}
-
-			// check that events re-order doesn't change forklessCause result
-			for reorderTry := 0; reorderTry < test.reorderChecks; reorderTry++ {
-				// re-order events randomly, preserving parents order
-				unordered := make(dag.Events, len(processedArr))
-				for i, j := range r.Perm(len(processedArr)) {
-					unordered[i] = processedArr[j]
+				vi.DropNotFlushed() // drops everything, because wasn't flushed
+				for _, e := range processed {
+					assertar.Nil(vi.HighestBefore(e.ID()))
+					assertar.Nil(vi.LowestAfter(e.ID()))
 				}
-				processedArr = tdag.ByParents(unordered)
 
-				for _, a := range processedArr {
-					assertar.NoError(vi.Add(a))
-				}
+				// check that events re-order doesn't change forklessCause result
+				for reorderTry := 0; reorderTry < test.reorderChecks; reorderTry++ {
+					// re-order events randomly, preserving parents order
+					unordered := make(dag.Events, len(processedArr))
+					for i, j := range r.Perm(len(processedArr)) {
+						unordered[i] = processedArr[j]
+					}
+					processedArr = tdag.ByParents(unordered)
 
-				vi.cache.ForklessCause.Purge() // disable cache
-				for _, a := range processedArr {
-					for _, b := range processedArr {
-						pair := kv{
-							a: a.ID(),
-							b: b.ID(),
+					for _, a := range processedArr {
+						assertar.NoError(vi.Add(a))
+					}
+
+					vi.PurgeCaches() // disable cache
+					for _, a := range processedArr {
+						for _, b := range processedArr {
+							pair := kv{
+								a: a.ID(),
+								b: b.ID(),
+							}
+							res := vi.ForklessCause(a.ID(), b.ID())
+							assertar.Equal(forklessCauseMap[pair], res, "%s %s %d", a.ID().String(), b.ID().String(), reorderTry)
 						}
-						res := vi.ForklessCause(a.ID(), b.ID())
-						assertar.Equal(forklessCauseMap[pair], res, "%s %s %d", a.ID().String(), b.ID().String(), reorderTry)
 					}
+					vi.DropNotFlushed()
 				}
-				vi.DropNotFlushed()
-			}
-		})
+			})
+		}
 	}
 }
 
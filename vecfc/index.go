@@ -8,7 +8,6 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/kvdb"
 	"github.com/Fantom-foundation/lachesis-base/kvdb/table"
 	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
-	"github.com/Fantom-foundation/lachesis-base/utils/simplewlru"
 	"github.com/Fantom-foundation/lachesis-base/vecengine"
 )
 
@@ -21,7 +20,13 @@ type IndexCacheConfig struct {
 
 // IndexConfig - Engine config (cache sizes)
 type IndexConfig struct {
+	// Caches are initial cache sizes
 	Caches IndexCacheConfig
+	// MinCaches and MaxCaches are bounds of adaptive cache sizes, cache isn't resized beyond its initial size if a bound is zero
+	MinCaches IndexCacheConfig
+	MaxCaches IndexCacheConfig
+	// CachesResizePeriod is a number of cache lookups between resizing decisions, caches aren't resized if zero
+	CachesResizePeriod int
 }
 
 // Index is a data to detect forkless-cause condition, calculate median timestamp, detect forks.
@@ -32,7 +37,8 @@ type Index struct {
 	validators    *pos.Validators
 	validatorIdxs map[idx.ValidatorID]idx.Validator
 
-	getEvent func(hash.Event) dag.Event
+	getEvent    func(hash.Event) dag.Event
+	onNewBranch func(creator idx.ValidatorID, branchID idx.Validator, firstEvent hash.Event)
 
 	vecDb kvdb.Store
 	table struct {
@@ -41,9 +47,9 @@ type Index struct {
 	}
 
 	cache struct {
-		HighestBeforeSeq *simplewlru.Cache
-		LowestAfterSeq   *simplewlru.Cache
-		ForklessCause    *simplewlru.Cache
+		HighestBeforeSeq *statCache
+		LowestAfterSeq   *statCache
+		ForklessCause    *statCache
 	}
 
 	cfg IndexConfig
@@ -60,6 +66,24 @@ func DefaultConfig(scale cachescale.Func) IndexConfig {
 	}
 }
 
+// AdaptiveConfig returns default index config with adaptive cache sizes
+func AdaptiveConfig(scale cachescale.Func) IndexConfig {
+	return IndexConfig{
+		Caches: DefaultConfig(scale).Caches,
+		MinCaches: IndexCacheConfig{
+			ForklessCausePairs:   scale.I(5000),
+			HighestBeforeSeqSize: scale.U(40 * 1024),
+			LowestAfterSeqSize:   scale.U(40 * 1024),
+		},
+		MaxCaches: IndexCacheConfig{
+			ForklessCausePairs:   scale.I(80000),
+			HighestBeforeSeqSize: scale.U(640 * 1024),
+			LowestAfterSeqSize:   scale.U(640 * 1024),
+		},
+		CachesResizePeriod: 10000,
+	}
+}
+
 // LiteConfig returns default index config for tests
 func LiteConfig() IndexConfig {
 	return DefaultConfig(cachescale.Ratio{Base: 100, Target: 1})
@@ -89,9 +113,28 @@ func NewIndexWithEngine(crit func(error), config IndexConfig, engine *vecengine.
 }
 
 func (vi *Index) initCaches() {
-	vi.cache.ForklessCause, _ = simplewlru.New(uint(vi.cfg.Caches.ForklessCausePairs), vi.cfg.Caches.ForklessCausePairs)
-	vi.cache.HighestBeforeSeq, _ = simplewlru.New(vi.cfg.Caches.HighestBeforeSeqSize, int(vi.cfg.Caches.HighestBeforeSeqSize))
-	vi.cache.LowestAfterSeq, _ = simplewlru.New(vi.cfg.Caches.LowestAfterSeqSize, int(vi.cfg.Caches.HighestBeforeSeqSize))
+	c := vi.cfg
+	vi.cache.ForklessCause = newStatCache(uint(c.Caches.ForklessCausePairs), uint(c.MinCaches.ForklessCausePairs), uint(c.MaxCaches.ForklessCausePairs), c.CachesResizePeriod)
+	vi.cache.HighestBeforeSeq = newStatCache(c.Caches.HighestBeforeSeqSize, c.MinCaches.HighestBeforeSeqSize, c.MaxCaches.HighestBeforeSeqSize, c.CachesResizePeriod)
+	vi.cache.LowestAfterSeq = newStatCache(c.Caches.LowestAfterSeqSize, c.MinCaches.LowestAfterSeqSize, c.MaxCaches.LowestAfterSeqSize, c.CachesResizePeriod)
+}
+
+// CacheStats returns the number of cache hits and misses since the Index creation
+func (vi *Index) CacheStats() IndexCacheStats {
+	return IndexCacheStats{
+		ForklessCause:    vi.cache.ForklessCause.stats,
+		HighestBeforeSeq: vi.cache.HighestBeforeSeq.stats,
+		LowestAfterSeq:   vi.cache.LowestAfterSeq.stats,
+	}
+}
+
+// CacheSizes returns the current sizes of the adaptive caches
+func (vi *Index) CacheSizes() IndexCacheConfig {
+	return IndexCacheConfig{
+		ForklessCausePairs:   int(vi.cache.ForklessCause.size),
+		HighestBeforeSeqSize: vi.cache.HighestBeforeSeq.size,
+		LowestAfterSeqSize:   vi.cache.LowestAfterSeq.size,
+	}
 }
 
 // Reset resets buffers.
@@ -126,9 +169,19 @@ func (vi *Index) GetEngineCallbacks() vecengine.Callbacks {
 		},
 		OnDbReset:        vi.onDbReset,
 		OnDropNotFlushed: vi.onDropNotFlushed,
+		OnNewBranch: func(creator idx.ValidatorID, branchID idx.Validator, firstEvent hash.Event) {
+			if vi.onNewBranch != nil {
+				vi.onNewBranch(creator, branchID, firstEvent)
+			}
+		},
 	}
 }
 
+// SetOnNewBranch sets a callback which is called for each new fork branch of a creator, once it's flushed
+func (vi *Index) SetOnNewBranch(fn func(creator idx.ValidatorID, branchID idx.Validator, firstEvent hash.Event)) {
+	vi.onNewBranch = fn
+}
+
 func (vi *Index) onDbReset(db kvdb.Store) {
 	vi.vecDb = db
 	table.MigrateTables(&vi.table, vi.vecDb)
@@ -0,0 +1,144 @@
+package vecfc
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/table"
+	"github.com/Fantom-foundation/lachesis-base/utils/wlru"
+	"github.com/Fantom-foundation/lachesis-base/vecengine"
+)
+
+// ReadOnlyIndex is a read-only view of Index, taken after a Flush.
+// Unlike Index, it's safe for concurrent use, and isn't affected by further modifications of Index.
+// Returned vectors are shared between goroutines, and must not be modified.
+// ReadOnlyIndex must be released after use, by calling Release method.
+type ReadOnlyIndex struct {
+	*vecengine.Snapshot
+
+	crit func(error)
+
+	table struct {
+		HighestBeforeSeq kvdb.IteratedReader `table:"S"`
+		LowestAfterSeq   kvdb.IteratedReader `table:"s"`
+	}
+
+	cache struct {
+		HighestBeforeSeq *wlru.Cache
+		LowestAfterSeq   *wlru.Cache
+		ForklessCause    *wlru.Cache
+	}
+}
+
+// ReadOnly returns a read-only view of the flushed vector clocks.
+// It should be called by the same goroutine which modifies Index, after Flush.
+func (vi *Index) ReadOnly() (*ReadOnlyIndex, error) {
+	snap, err := vi.Engine.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	ro := &ReadOnlyIndex{
+		Snapshot: snap,
+		crit:     vi.crit,
+	}
+	table.MigrateReaders(&ro.table, snap.DB())
+
+	ro.cache.ForklessCause, _ = wlru.New(uint(vi.cfg.Caches.ForklessCausePairs), vi.cfg.Caches.ForklessCausePairs)
+	ro.cache.HighestBeforeSeq, _ = wlru.New(vi.cfg.Caches.HighestBeforeSeqSize, int(vi.cfg.Caches.HighestBeforeSeqSize))
+	ro.cache.LowestAfterSeq, _ = wlru.New(vi.cfg.Caches.LowestAfterSeqSize, int(vi.cfg.Caches.LowestAfterSeqSize))
+
+	return ro, nil
+}
+
+func (ro *ReadOnlyIndex) getBytes(table kvdb.Reader, id hash.Event) []byte {
+	b, err := table.Get(id.Bytes())
+	if err != nil {
+		ro.crit(err)
+	}
+	return b
+}
+
+// GetLowestAfter reads the vector from DB snapshot
+func (ro *ReadOnlyIndex) GetLowestAfter(id hash.Event) *LowestAfterSeq {
+	if bVal, okGet := ro.cache.LowestAfterSeq.Get(id); okGet {
+		return bVal.(*LowestAfterSeq)
+	}
+
+	b := LowestAfterSeq(ro.getBytes(ro.table.LowestAfterSeq, id))
+	if b == nil {
+		return nil
+	}
+	ro.cache.LowestAfterSeq.Add(id, &b, uint(len(b)))
+	return &b
+}
+
+// GetHighestBefore reads the vector from DB snapshot
+func (ro *ReadOnlyIndex) GetHighestBefore(id hash.Event) *HighestBeforeSeq {
+	if bVal, okGet := ro.cache.HighestBeforeSeq.Get(id); okGet {
+		return bVal.(*HighestBeforeSeq)
+	}
+
+	b := HighestBeforeSeq(ro.getBytes(ro.table.HighestBeforeSeq, id))
+	if b == nil {
+		return nil
+	}
+	ro.cache.HighestBeforeSeq.Add(id, &b, uint(len(b)))
+	return &b
+}
+
+// GetMergedHighestBefore returns HighestBefore vector clock without branches, where branches are merged into one
+func (ro *ReadOnlyIndex) GetMergedHighestBefore(id hash.Event) *HighestBeforeSeq {
+	if ro.AtLeastOneFork() {
+		scatteredBefore := ro.GetHighestBefore(id)
+
+		mergedBefore := NewHighestBeforeSeq(ro.Validators().Len())
+
+		for creatorIdx, branches := range ro.BranchesInfo().BranchIDByCreators {
+			mergedBefore.GatherFrom(idx.Validator(creatorIdx), scatteredBefore, branches)
+		}
+
+		return mergedBefore
+	}
+	return ro.GetHighestBefore(id)
+}
+
+// ForklessCause calculates "sufficient coherence" between the events.
+// See Index.ForklessCause for details.
+func (ro *ReadOnlyIndex) ForklessCause(aID, bID hash.Event) bool {
+	if res, ok := ro.cache.ForklessCause.Get(kv{aID, bID}); ok {
+		return res.(bool)
+	}
+
+	res := ro.forklessCause(aID, bID)
+
+	ro.cache.ForklessCause.Add(kv{aID, bID}, res, 1)
+	return res
+}
+
+func (ro *ReadOnlyIndex) forklessCause(aID, bID hash.Event) bool {
+	// Get events by hash
+	a := ro.GetHighestBefore(aID)
+	if a == nil {
+		ro.crit(fmt.Errorf("Event A=%s not found", aID.String()))
+		return false
+	}
+
+	// check A doesn't observe any forks from B
+	if ro.AtLeastOneFork() {
+		bBranchID := ro.GetEventBranchID(bID)
+		if a.Get(bBranchID).IsForkDetected() { // B is observed as cheater by A
+			return false
+		}
+	}
+
+	// check A observes that {QUORUM} non-cheater-validators observe B
+	b := ro.GetLowestAfter(bID)
+	if b == nil {
+		ro.crit(fmt.Errorf("Event B=%s not found", bID.String()))
+		return false
+	}
+
+	return ForklessCauseByVectors(ro.Validators(), ro.BranchesInfo(), a, b)
+}
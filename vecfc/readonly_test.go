@@ -0,0 +1,111 @@
+package vecfc
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/Fantom-foundation/lachesis-base/vecengine"
+)
+
+func TestReadOnlyIndex(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(8)
+	cheaters := nodes[:2]
+	validators := pos.EqualWeightValidators(nodes, 1)
+
+	var ordered dag.Events
+	_ = tdag.ForEachRandFork(nodes, cheaters, 30, 4, 5, rand.New(rand.NewSource(0)), tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+		},
+	})
+	ordered = tdag.ByParents(ordered)
+
+	var mu sync.Mutex
+	processed := make(map[hash.Event]dag.Event)
+	getEvent := func(id hash.Event) dag.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return processed[id]
+	}
+
+	vi := NewIndex(tCrit, LiteConfig())
+	vi.Reset(validators, memorydb.New(), getEvent)
+
+	assertar := assert.New(t)
+	add := func(e dag.Event) {
+		mu.Lock()
+		processed[e.ID()] = e
+		mu.Unlock()
+		assertar.NoError(vi.Add(e))
+	}
+
+	// the first half is flushed and visible for the read-only view
+	half := len(ordered) / 2
+	for _, e := range ordered[:half] {
+		add(e)
+	}
+
+	_, err := vi.ReadOnly()
+	require.Equal(vecengine.ErrNotFlushed, err)
+	vi.Flush()
+
+	expected := map[kv]bool{}
+	for _, a := range ordered[:half] {
+		for _, b := range ordered[:half] {
+			expected[kv{a.ID(), b.ID()}] = vi.ForklessCause(a.ID(), b.ID())
+		}
+	}
+
+	ro, err := vi.ReadOnly()
+	require.NoError(err)
+	defer ro.Release()
+
+	wg := sync.WaitGroup{}
+	// writer keeps modifying the index
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, e := range ordered[half:] {
+			add(e)
+			if i%2 == 0 {
+				vi.Flush()
+			} else {
+				vi.DropNotFlushed()
+				add(e)
+				vi.Flush()
+			}
+		}
+	}()
+	// readers query the read-only view
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for _, i := range rand.New(rand.NewSource(int64(r))).Perm(half) {
+				a := ordered[i]
+				assertar.NotNil(ro.GetMergedHighestBefore(a.ID()))
+				for _, b := range ordered[:half] {
+					assertar.Equal(expected[kv{a.ID(), b.ID()}], ro.ForklessCause(a.ID(), b.ID()))
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	// events which were added after the view is taken aren't visible
+	for _, e := range ordered[half:] {
+		require.Nil(ro.GetHighestBefore(e.ID()))
+		require.Nil(ro.GetLowestAfter(e.ID()))
+	}
+}
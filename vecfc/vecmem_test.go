@@ -0,0 +1,40 @@
+package vecfc_test
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/vecfc"
+	"github.com/Fantom-foundation/lachesis-base/vecmem"
+)
+
+// the shared test suite runs against vecmem too,
+// it's registered from the external package because vecmem imports vecfc
+func init() {
+	vecfc.RegisterTestBackend("vecmem", func() vecfc.TestIndexer {
+		// no cache, so re-computation is always checked
+		return memIndexer{vecmem.NewIndex(func(err error) { panic(err) }, vecmem.IndexConfig{})}
+	})
+}
+
+type memIndexer struct {
+	*vecmem.Index
+}
+
+func (vi memIndexer) HighestBefore(id hash.Event) vecfc.HighestBeforeReader {
+	if v := vi.GetHighestBefore(id); v != nil {
+		return v
+	}
+	return nil
+}
+
+func (vi memIndexer) LowestAfter(id hash.Event) vecfc.LowestAfterReader {
+	if v := vi.GetLowestAfter(id); v != nil {
+		return v
+	}
+	return nil
+}
+
+func (vi memIndexer) MergedHighestBefore(id hash.Event) []vecfc.BranchSeq {
+	return *vi.GetMergedHighestBefore(id)
+}
+
+func (vi memIndexer) PurgeCaches() {}
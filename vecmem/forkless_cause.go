@@ -0,0 +1,52 @@
+package vecmem
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/vecfc"
+)
+
+type kv struct {
+	a, b hash.Event
+}
+
+// ForklessCause calculates "sufficient coherence" between the events.
+// See vecfc.Index.ForklessCause for details.
+func (vi *Index) ForklessCause(aID, bID hash.Event) bool {
+	if res, ok := vi.cache.ForklessCause.Get(kv{aID, bID}); ok {
+		return res.(bool)
+	}
+
+	vi.Engine.InitBranchesInfo()
+	res := vi.forklessCause(aID, bID)
+
+	vi.cache.ForklessCause.Add(kv{aID, bID}, res, 1)
+	return res
+}
+
+func (vi *Index) forklessCause(aID, bID hash.Event) bool {
+	// Get events by hash
+	a := vi.GetHighestBefore(aID)
+	if a == nil {
+		vi.crit(fmt.Errorf("Event A=%s not found", aID.String()))
+		return false
+	}
+
+	// check A doesn't observe any forks from B
+	if vi.Engine.AtLeastOneFork() {
+		bBranchID := vi.Engine.GetEventBranchID(bID)
+		if a.Get(bBranchID).IsForkDetected() { // B is observed as cheater by A
+			return false
+		}
+	}
+
+	// check A observes that {QUORUM} non-cheater-validators observe B
+	b := vi.GetLowestAfter(bID)
+	if b == nil {
+		vi.crit(fmt.Errorf("Event B=%s not found", bID.String()))
+		return false
+	}
+
+	return vecfc.ForklessCauseByVectors(vi.validators, vi.Engine.BranchesInfo(), a, b)
+}
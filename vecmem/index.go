@@ -0,0 +1,176 @@
+package vecmem
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
+	"github.com/Fantom-foundation/lachesis-base/utils/simplewlru"
+	"github.com/Fantom-foundation/lachesis-base/vecengine"
+)
+
+// IndexCacheConfig - config for cache sizes of Engine
+type IndexCacheConfig struct {
+	ForklessCausePairs int
+}
+
+// IndexConfig - Engine config (cache sizes)
+type IndexConfig struct {
+	Caches IndexCacheConfig
+}
+
+type vectors struct {
+	highestBefore map[hash.Event]*HighestBeforeSeq
+	lowestAfter   map[hash.Event]*LowestAfterSeq
+}
+
+func newVectors() vectors {
+	return vectors{
+		highestBefore: make(map[hash.Event]*HighestBeforeSeq),
+		lowestAfter:   make(map[hash.Event]*LowestAfterSeq),
+	}
+}
+
+// Index is a pure in-memory alternative to vecfc.Index.
+// It keeps vectors as native Go slices in maps, instead of byte-encoded records in a key-value DB.
+// It's intended for simulations and benchmarks, because it isn't persistent.
+type Index struct {
+	*vecengine.Engine
+
+	crit       func(error)
+	validators *pos.Validators
+
+	getEvent func(hash.Event) dag.Event
+
+	flushed    vectors
+	notFlushed vectors
+
+	cache struct {
+		ForklessCause *simplewlru.Cache
+	}
+
+	cfg IndexConfig
+}
+
+// DefaultConfig returns default index config
+func DefaultConfig(scale cachescale.Func) IndexConfig {
+	return IndexConfig{
+		Caches: IndexCacheConfig{
+			ForklessCausePairs: scale.I(20000),
+		},
+	}
+}
+
+// LiteConfig returns default index config for tests
+func LiteConfig() IndexConfig {
+	return DefaultConfig(cachescale.Ratio{Base: 100, Target: 1})
+}
+
+// NewIndex creates Index instance.
+func NewIndex(crit func(error), config IndexConfig) *Index {
+	vi := &Index{
+		cfg:        config,
+		crit:       crit,
+		flushed:    newVectors(),
+		notFlushed: newVectors(),
+	}
+	vi.Engine = vecengine.NewIndex(crit, vi.GetEngineCallbacks())
+	vi.cache.ForklessCause, _ = simplewlru.New(uint(vi.cfg.Caches.ForklessCausePairs), vi.cfg.Caches.ForklessCausePairs)
+
+	return vi
+}
+
+// Reset resets buffers.
+// The DB argument is ignored, all the data is kept in memory.
+func (vi *Index) Reset(validators *pos.Validators, _ kvdb.Store, getEvent func(hash.Event) dag.Event) {
+	vi.flushed = newVectors()
+	vi.notFlushed = newVectors()
+	vi.Engine.ResetInMemory(validators, getEvent)
+	vi.getEvent = getEvent
+	vi.validators = validators
+	vi.cache.ForklessCause.Purge()
+}
+
+// Flush commits the vector clocks.
+func (vi *Index) Flush() {
+	vi.Engine.Flush()
+	for id, v := range vi.notFlushed.highestBefore {
+		vi.flushed.highestBefore[id] = v
+	}
+	for id, v := range vi.notFlushed.lowestAfter {
+		vi.flushed.lowestAfter[id] = v
+	}
+	vi.notFlushed = newVectors()
+}
+
+func (vi *Index) GetEngineCallbacks() vecengine.Callbacks {
+	return vecengine.Callbacks{
+		GetHighestBefore: func(event hash.Event) vecengine.HighestBeforeI {
+			return vi.GetHighestBefore(event)
+		},
+		GetLowestAfter: func(event hash.Event) vecengine.LowestAfterI {
+			return vi.getLowestAfterForUpdate(event)
+		},
+		SetHighestBefore: func(event hash.Event, b vecengine.HighestBeforeI) {
+			vi.SetHighestBefore(event, b.(*HighestBeforeSeq))
+		},
+		SetLowestAfter: func(event hash.Event, b vecengine.LowestAfterI) {
+			vi.SetLowestAfter(event, b.(*LowestAfterSeq))
+		},
+		NewHighestBefore: func(size idx.Validator) vecengine.HighestBeforeI {
+			return NewHighestBeforeSeq(size)
+		},
+		NewLowestAfter: func(size idx.Validator) vecengine.LowestAfterI {
+			return NewLowestAfterSeq(size)
+		},
+		OnDropNotFlushed: vi.onDropNotFlushed,
+	}
+}
+
+func (vi *Index) onDropNotFlushed() {
+	vi.notFlushed = newVectors()
+}
+
+// GetLowestAfter returns the vector. The result must not be modified.
+func (vi *Index) GetLowestAfter(id hash.Event) *LowestAfterSeq {
+	if v, ok := vi.notFlushed.lowestAfter[id]; ok {
+		return v
+	}
+	return vi.flushed.lowestAfter[id]
+}
+
+// getLowestAfterForUpdate returns a vector which may be modified without affecting the flushed state
+func (vi *Index) getLowestAfterForUpdate(id hash.Event) vecengine.LowestAfterI {
+	if v, ok := vi.notFlushed.lowestAfter[id]; ok {
+		return v
+	}
+	if v, ok := vi.flushed.lowestAfter[id]; ok {
+		return v.Copy()
+	}
+	return nil
+}
+
+// GetHighestBefore returns the vector. The result must not be modified.
+func (vi *Index) GetHighestBefore(id hash.Event) *HighestBeforeSeq {
+	if v, ok := vi.notFlushed.highestBefore[id]; ok {
+		return v
+	}
+	return vi.flushed.highestBefore[id]
+}
+
+// SetLowestAfter stores the vector
+func (vi *Index) SetLowestAfter(id hash.Event, seq *LowestAfterSeq) {
+	vi.notFlushed.lowestAfter[id] = seq
+}
+
+// SetHighestBefore stores the vector
+func (vi *Index) SetHighestBefore(id hash.Event, seq *HighestBeforeSeq) {
+	vi.notFlushed.highestBefore[id] = seq
+}
+
+// GetMergedHighestBefore returns HighestBefore vector clock without branches, where branches are merged into one
+func (vi *Index) GetMergedHighestBefore(id hash.Event) *HighestBeforeSeq {
+	return vi.Engine.GetMergedHighestBefore(id).(*HighestBeforeSeq)
+}
@@ -0,0 +1,119 @@
+package vecmem
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag/tdag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/Fantom-foundation/lachesis-base/vecengine"
+	"github.com/Fantom-foundation/lachesis-base/vecfc"
+)
+
+func tCrit(err error) { panic(err) }
+
+// The index is covered by the shared vecfc test suite, see vecfc/vecmem_test.go
+
+func TestIndex_SameForksAsVecfc(t *testing.T) {
+	require := require.New(t)
+
+	nodes := tdag.GenNodes(8)
+	cheaters := nodes[:3]
+	validators := pos.EqualWeightValidators(nodes, 1)
+
+	processed := make(map[hash.Event]dag.Event)
+	getEvent := func(id hash.Event) dag.Event {
+		return processed[id]
+	}
+
+	mem := NewIndex(tCrit, LiteConfig())
+	mem.Reset(validators, nil, getEvent)
+	db := vecfc.NewIndex(tCrit, vecfc.LiteConfig())
+	db.Reset(validators, memorydb.New(), getEvent)
+
+	var ordered dag.Events
+	_ = tdag.ForEachRandFork(nodes, cheaters, 30, 3, 5, rand.New(rand.NewSource(0)), tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			if _, ok := processed[e.ID()]; ok {
+				return
+			}
+			processed[e.ID()] = e
+			ordered = append(ordered, e)
+
+			// dropped changes must not affect the branches data
+			require.NoError(mem.Add(e))
+			mem.DropNotFlushed()
+			require.NoError(db.Add(e))
+			db.DropNotFlushed()
+
+			require.NoError(mem.Add(e))
+			mem.Flush()
+			require.NoError(db.Add(e))
+			db.Flush()
+		},
+	})
+
+	require.True(db.AtLeastOneFork())
+	require.Equal(db.AtLeastOneFork(), mem.AtLeastOneFork())
+	require.Equal(db.BranchesInfo(), mem.BranchesInfo())
+	require.Equal(db.GetAllBranches(), mem.GetAllBranches())
+
+	for _, a := range ordered {
+		require.Equal(db.GetEventBranchID(a.ID()), mem.GetEventBranchID(a.ID()))
+
+		expMerged := db.GetMergedHighestBefore(a.ID())
+		gotMerged := mem.GetMergedHighestBefore(a.ID())
+		require.Equal(expMerged.Size(), gotMerged.Size())
+		for i := idx.Validator(0); i < idx.Validator(expMerged.Size()); i++ {
+			require.Equal(expMerged.Get(i), gotMerged.Get(i))
+		}
+
+		for _, b := range ordered {
+			require.Equal(db.ForklessCause(a.ID(), b.ID()), mem.ForklessCause(a.ID(), b.ID()))
+		}
+	}
+
+	_, err := mem.GetSnapshot()
+	require.Equal(vecengine.ErrNoDB, err)
+}
+
+func BenchmarkIndex_Add(b *testing.B) {
+	nodes := tdag.GenNodes(20)
+	validators := pos.EqualWeightValidators(nodes, 1)
+	var ordered dag.Events
+	_ = tdag.ForEachRandEvent(nodes, 50, 5, rand.New(rand.NewSource(0)), tdag.ForEachEvent{
+		Process: func(e dag.Event, name string) {
+			ordered = append(ordered, e)
+		},
+	})
+	events := make(map[hash.Event]dag.Event)
+	for _, e := range ordered {
+		events[e.ID()] = e
+	}
+	getEvent := func(id hash.Event) dag.Event {
+		return events[id]
+	}
+
+	vi := NewIndex(tCrit, LiteConfig())
+	for i := 0; i < b.N; {
+		b.StopTimer()
+		vi.Reset(validators, nil, getEvent)
+		b.StartTimer()
+		for _, e := range ordered {
+			if err := vi.Add(e); err != nil {
+				panic(err)
+			}
+			vi.Flush()
+			i++
+			if i >= b.N {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package vecmem
+
+import (
+	"math"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/vecfc"
+)
+
+/*
+ * Use native slices instead of the binary form, because vectors are never serialized.
+ */
+
+type (
+	// LowestAfterSeq is a vector of lowest events (their Seq) which do observe the source event
+	LowestAfterSeq []idx.Event
+	// HighestBeforeSeq is a vector of highest events (their Seq + IsForkDetected) which are observed by source event
+	HighestBeforeSeq []vecfc.BranchSeq
+)
+
+var (
+	// forkDetectedSeq is a special marker of observed fork by a creator
+	forkDetectedSeq = vecfc.BranchSeq{
+		Seq:    0,
+		MinSeq: idx.Event(math.MaxInt32),
+	}
+)
+
+// NewLowestAfterSeq creates new LowestAfterSeq vector.
+func NewLowestAfterSeq(size idx.Validator) *LowestAfterSeq {
+	b := make(LowestAfterSeq, size)
+	return &b
+}
+
+// NewHighestBeforeSeq creates new HighestBeforeSeq vector.
+func NewHighestBeforeSeq(size idx.Validator) *HighestBeforeSeq {
+	b := make(HighestBeforeSeq, size)
+	return &b
+}
+
+// Get i's position in the vector clock
+func (b LowestAfterSeq) Get(i idx.Validator) idx.Event {
+	if i >= b.Size() {
+		return 0
+	}
+	return b[i]
+}
+
+// Size of the vector clock
+func (b LowestAfterSeq) Size() idx.Validator {
+	return idx.Validator(len(b))
+}
+
+// Set i's position in the vector clock
+func (b *LowestAfterSeq) Set(i idx.Validator, seq idx.Event) {
+	for i >= b.Size() {
+		// append zeros if exceeds size
+		*b = append(*b, 0)
+	}
+	(*b)[i] = seq
+}
+
+// Copy returns a copy of the vector clock
+func (b LowestAfterSeq) Copy() *LowestAfterSeq {
+	cp := make(LowestAfterSeq, len(b))
+	copy(cp, b)
+	return &cp
+}
+
+// Size of the vector clock
+func (b HighestBeforeSeq) Size() int {
+	return len(b)
+}
+
+// Get i's position in the vector clock
+func (b HighestBeforeSeq) Get(i idx.Validator) vecfc.BranchSeq {
+	if int(i) >= b.Size() {
+		return vecfc.BranchSeq{}
+	}
+	return b[i]
+}
+
+// Set i's position in the vector clock
+func (b *HighestBeforeSeq) Set(i idx.Validator, seq vecfc.BranchSeq) {
+	for int(i) >= b.Size() {
+		// append zeros if exceeds size
+		*b = append(*b, vecfc.BranchSeq{})
+	}
+	(*b)[i] = seq
+}